@@ -0,0 +1,204 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/noypi/math0"
+)
+
+// Add returns a + b. The operands are not modified.
+func Add(a, b IExpression) IExpression {
+	return newExprOf(append(cloneTerms(a), cloneTerms(b)...)...)
+}
+
+// Sub returns a - b.
+func Sub(a, b IExpression) IExpression {
+	return Add(a, Neg(b))
+}
+
+// Neg returns -e.
+func Neg(e IExpression) IExpression {
+	return scaleExpr(e, -1)
+}
+
+// Mul returns the expanded product a * b.
+func Mul(a, b IExpression) IExpression {
+	var terms TermList
+	a.EachTerm(func(ta ITerm) bool {
+		b.EachTerm(func(tb ITerm) bool {
+			terms = append(terms, mulTerm(ta, tb))
+			return true
+		})
+		return true
+	})
+	return newExprOf(terms...)
+}
+
+// Pow returns the expanded e^n. A negative n is only supported when e is a
+// single term: a zero e is an ErrDivisionByZero and several terms are an
+// ErrNotPolynomial, as the result is not a sum of terms.
+func Pow(e IExpression, n int) (IExpression, error) {
+	if 0 > n {
+		terms := e.Terms()
+		if 1 < len(terms) {
+			return nil, ErrNotPolynomial(fmt.Errorf("(%s)^%d is not a sum of terms", e, n))
+		}
+		if 0 == len(terms) || isZeroCoeff(coeffOf(terms[0])) {
+			return nil, ErrDivisionByZero(fmt.Errorf("(%s)^%d is a division by zero", e, n))
+		}
+		vs := make(VariableList, 0, len(terms[0].Vars()))
+		for _, v := range terms[0].Vars() {
+			vs = append(vs, withPower(v, -v.Power()))
+		}
+		e, n = newExprOf(newTermC(1/coeffOf(terms[0]), vs...)), -n
+	}
+
+	out := newExprOf(EqnBuilder_TermConstructor(1.0))
+	for base := e; 0 < n; n >>= 1 {
+		if 1 == n&1 {
+			out = Mul(out, base)
+		}
+		if 1 < n {
+			base = Mul(base, base)
+		}
+	}
+	return out, nil
+}
+
+// PowReal returns e^n for a real n, an integral n as Pow does. A single
+// term is raised factor by factor where that keeps its value wherever it is
+// defined, e.g. (4x^3)^0.5 is 2x^1.5 and (x^2)^0.5 is abs(x); otherwise,
+// and for several terms, e is raised as pow(e, n). A negative constant is
+// an ErrDomain and zero to a negative power an ErrDivisionByZero.
+func PowReal(e IExpression, n float64) (IExpression, error) {
+	if isIntegral(n) {
+		return Pow(e, int(math.Round(n)))
+	}
+
+	terms := e.Terms()
+	switch {
+	case 0 == len(terms) && 0 > n:
+		return nil, ErrDivisionByZero(fmt.Errorf("0^%s is a division by zero", ToTrimZero(n)))
+	case 0 == len(terms):
+		return newExprOf(), nil
+	case 1 < len(terms):
+		return FuncExpr(FuncPow, 1, e, newExprOf(NewTerm(n))), nil
+	}
+
+	c := coeffOf(terms[0])
+	if !isComplexCoeff(c) && 0 < real(c) {
+		if vs, ok := powVars(terms[0].Vars(), n); ok {
+			return newExprOf(EqnBuilder_TermConstructor(math.Pow(real(c), n), vs...)), nil
+		}
+	}
+	if 0 == len(terms[0].Vars()) {
+		return nil, ErrDomain(fmt.Errorf("(%s)^%s is undefined", e, ToTrimZero(n)))
+	}
+	return FuncExpr(FuncPow, 1, e, newExprOf(NewTerm(n))), nil
+}
+
+// powVars raises the factors of a positive term to a non-integral n, false
+// if that could change its value or where it is defined. An even power of v
+// is that of abs(v). A non-integral power of v is only defined for v >= 0,
+// and so is the term if v has an odd power and is its only factor.
+func powVars(vs VariableList, n float64) (VariableList, bool) {
+	out := make(VariableList, 0, len(vs))
+	for _, v := range vs {
+		p := v.Power()
+		switch {
+		case isIntegral(p) && isEven(p) && isIntegral(p*n) && isEven(p*n):
+			out = append(out, withPower(v, p*n))
+		case isIntegral(p) && isEven(p):
+			out = append(out, NewFuncN(FuncAbs, p*n, newExprOf(NewTerm(1, withPower(v, 1)))))
+		case !isIntegral(p*n) && (!isIntegral(p) || 1 == len(vs)):
+			out = append(out, withPower(v, p*n))
+		default:
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+// isEven reports whether an integral f is even.
+func isEven(f float64) bool {
+	return 0 == math.Mod(math.Round(f), 2)
+}
+
+// newExprOf drops zero terms, then builds the expression.
+func newExprOf(terms ...ITerm) IExpression {
+	out := make(TermList, 0, len(terms))
+	for _, term := range terms {
+		if !isZeroCoeff(coeffOf(term)) {
+			out = append(out, term)
+		}
+	}
+	return EqnBuilder_ExprConstructor(out...)
+}
+
+// ConstantOf returns the value of e and true if e has no variables and no
+// imaginary part.
+func ConstantOf(e IExpression) (c float64, isConst bool) {
+	isConst = true
+	e.EachTerm(func(term ITerm) bool {
+		if 0 < len(term.Vars()) || isComplexCoeff(coeffOf(term)) {
+			isConst = false
+			return false
+		}
+		c += term.C()
+		return true
+	})
+	return
+}
+
+func cloneTerms(e IExpression) TermList {
+	var out TermList
+	e.EachTerm(func(term ITerm) bool {
+		out = append(out, term.Clone())
+		return true
+	})
+	return out
+}
+
+func scaleExpr(e IExpression, c float64) IExpression {
+	terms := cloneTerms(e)
+	for i, term := range terms {
+		terms[i] = withCoeff(term, coeffOf(term)*complex(c, 0))
+	}
+	return newExprOf(terms...)
+}
+
+func mulTerm(a, b ITerm) ITerm {
+	return newTermC(coeffOf(a)*coeffOf(b), mulVars(a.Vars(), b.Vars())...)
+}
+
+// withPower returns v raised to power instead, a function keeping its node
+// rather than becoming a variable named by its text.
+func withPower(v IVariable, power float64) IVariable {
+	if fn, isFunc := v.(IFunction); isFunc {
+		return NewFuncN(fn.Kind(), power, fn.Args()...)
+	}
+	return EqnBuilder_VarConstructor(v.Name(), power)
+}
+
+// mulVars merges the powers of equally named variables.
+func mulVars(a, b VariableList) VariableList {
+	vs := make(VariableList, 0, len(a)+len(b))
+	vs = append(vs, a...)
+	vs = append(vs, b...)
+	sort.SliceStable(vs, vs.Less)
+
+	out := make(VariableList, 0, len(vs))
+	for _, v := range vs {
+		if n := len(out); 0 < n && out[n-1].Name() == v.Name() {
+			out[n-1] = out[n-1].AddPower(v.Power())
+			if math0.IsApproxEqual(out[n-1].Power(), 0.0) {
+				out = out[:n-1]
+			}
+		} else if !math0.IsApproxEqual(v.Power(), 0.0) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
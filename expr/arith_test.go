@@ -0,0 +1,131 @@
+package expr
+
+import (
+	"math"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func mustParse(s string) IExpression {
+	e, err := ParseExpr(s)
+	if nil != err {
+		panic(err)
+	}
+	return e
+}
+
+func mustPow(e IExpression, n int) IExpression {
+	out, err := Pow(e, n)
+	if nil != err {
+		panic(err)
+	}
+	return out
+}
+
+func TestArith(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	a := mustParse("x + 1")
+	b := mustParse("x*y - 2")
+
+	assert.Equal("-1 + 1(x) + 1(x*y)", Add(a, b).String())
+	assert.Equal("3 + 1(x) + -1(x*y)", Sub(a, b).String())
+	assert.Equal("-1 + -1(x)", Neg(a).String())
+	assert.Equal("-2 + -2(x) + 1(x*y) + 1(x^2*y)", Mul(a, b).String())
+	assert.Equal("1 + 3(x) + 3(x^2) + 1(x^3)", mustPow(a, 3).String())
+	assert.Equal("1", mustPow(a, 0).String())
+	_, err := Pow(a, -1)
+	assert.NotNil(err)
+	_, err = Pow(mustParse("x - x"), -2)
+	assert.NotNil(err)
+	inv := mustPow(mustParse("2sin(x)^2*y"), -1)
+	assert.Equal("0.5(sin(x)^-2*y^-1)", inv.String())
+	_, isFunc := inv.Terms()[0].Vars()[0].(IFunction)
+	assert.True(isFunc)
+	assert.Equal("0", Sub(a, a).String())
+
+	// operands are left untouched
+	assert.Equal("1 + 1(x)", a.String())
+	assert.Equal("-2 + 1(x*y)", b.String())
+}
+
+func TestArith_MergePowers(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e := Mul(mustParse("x^2*y"), mustParse("x*y^3*z"))
+	assert.Equal("1(x^3*y^4*z)", e.String())
+
+	term := NewTerm(2, NewVar("y"), NewVar("x"), NewVar("y"))
+	assert.Equal("2(x*y^2)", term.String())
+}
+
+type _powrealtest struct {
+	s        string
+	n        float64
+	expected string
+}
+
+var ttPowReal = []_powrealtest{
+	//0
+	_powrealtest{"4x^3", 0.5, "2(x^1.5)"},
+	//1
+	_powrealtest{"x^2", 0.5, "1(abs(x))"},
+	//2
+	_powrealtest{"x^4", 0.5, "1(x^2)"},
+	//3
+	_powrealtest{"9x^2", 1.5, "27(abs(x)^3)"},
+	//4
+	_powrealtest{"x^-1", 0.5, "1(x^-0.5)"},
+	//5
+	_powrealtest{"x^0.5*y^2", 0.5, "1(abs(y)*x^0.25)"},
+	//6
+	_powrealtest{"sin(x)^2", 0.5, "1(abs(sin(x)))"},
+	//7
+	_powrealtest{"x*y", 0.5, "1(pow(x*y, 0.5))"},
+	//8
+	_powrealtest{"-x", 0.5, "1(pow(-x, 0.5))"},
+	//9
+	_powrealtest{"x^3", 1.0 / 3, "1(pow(x^3, 0.3333333333333333))"},
+	//10
+	_powrealtest{"x - x", 0.5, "0"},
+	//11
+	_powrealtest{"x + 1", 2, "1 + 2(x) + 1(x^2)"},
+	//12
+	_powrealtest{"x^2 - 2x + 1", 0.5, "1(pow(1 + -2*x + x^2, 0.5))"},
+}
+
+func TestPowReal(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for i, tt := range ttPowReal {
+		base := mustParse(tt.s)
+		e, err := PowReal(base, tt.n)
+		if !assert.Nil(err, "i=%d", i) {
+			continue
+		}
+		assert.Equal(tt.expected, e.String(), "i=%d", i)
+
+		// the same value wherever base^n is defined, and nowhere else
+		for _, x := range []float64{-2, -0.5, 0.5, 3} {
+			for _, y := range []float64{-1, 1.5} {
+				m := Valuation{"x": x, "y": y}
+				b, err := ValueOfExpr(base, m)
+				if nil != err {
+					continue
+				}
+				v, err := ValueOfExpr(e, m)
+				if 0 > b && !isIntegral(tt.n) {
+					assert.NotNil(err, "i=%d at %v", i, m)
+				} else if assert.Nil(err, "i=%d at %v", i, m) {
+					assert.InDelta(math.Pow(b, tt.n), v, 1e-9, "i=%d at %v", i, m)
+				}
+			}
+		}
+	}
+
+	for _, tt := range []_powrealtest{{"-4", 0.5, ""}, {"0", -0.5, ""}, {"x + 1", -1, ""}} {
+		_, err := PowReal(mustParse(tt.s), tt.n)
+		assert.NotNil(err, "%s ^ %v", tt.s, tt.n)
+	}
+}
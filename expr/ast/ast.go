@@ -0,0 +1,269 @@
+// Package ast holds expressions as trees, so that unexpanded forms like
+// (x+1)*(y-2) or x/(y+1) keep their structure. ToPolynomial and
+// FromExpression convert to and from the canonical sum of terms of package
+// expr.
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/noypi/math0/expr"
+)
+
+type Node interface {
+	fmt.Stringer
+	Eval(m expr.IValuation) (float64, error)
+}
+
+type Const struct {
+	Value float64
+}
+
+type VarRef struct {
+	Name string
+}
+
+// Add is the sum of its terms.
+type Add struct {
+	Terms []Node
+}
+
+// Mul is the product of its factors.
+type Mul struct {
+	Factors []Node
+}
+
+type Div struct {
+	Num, Den Node
+}
+
+type Pow struct {
+	Base, Exp Node
+}
+
+// Func applies Kind to Args, FuncPow takes two args and the others one.
+type Func struct {
+	Kind expr.FuncKind
+	Args []Node
+}
+
+// precedences used by String to place parentheses
+const (
+	precAdd = iota + 1
+	precMul
+	precUnary
+	precPow
+	precAtom
+)
+
+func (this *Const) Eval(m expr.IValuation) (float64, error) {
+	return this.Value, nil
+}
+
+func (this *VarRef) Eval(m expr.IValuation) (float64, error) {
+	f, has := m.Get(this.Name)
+	if !has {
+		return 0, expr.ErrNoValuationForVar(fmt.Errorf("var=%s has no valuation", this.Name))
+	}
+	return f, nil
+}
+
+func (this *Add) Eval(m expr.IValuation) (value float64, err error) {
+	for _, n := range this.Terms {
+		var f float64
+		if f, err = n.Eval(m); nil != err {
+			return
+		}
+		value += f
+	}
+	return
+}
+
+func (this *Mul) Eval(m expr.IValuation) (value float64, err error) {
+	value = 1.0
+	for _, n := range this.Factors {
+		var f float64
+		if f, err = n.Eval(m); nil != err {
+			return
+		}
+		value *= f
+	}
+	return
+}
+
+func (this *Div) Eval(m expr.IValuation) (float64, error) {
+	num, err := this.Num.Eval(m)
+	if nil != err {
+		return 0, err
+	}
+	den, err := this.Den.Eval(m)
+	if nil != err {
+		return 0, err
+	}
+	if 0 == den {
+		return 0, expr.ErrDivisionByZero(fmt.Errorf("division of %v by zero", num))
+	}
+	return num / den, nil
+}
+
+func (this *Pow) Eval(m expr.IValuation) (float64, error) {
+	base, err := this.Base.Eval(m)
+	if nil != err {
+		return 0, err
+	}
+	exp, err := this.Exp.Eval(m)
+	if nil != err {
+		return 0, err
+	}
+	return expr.FuncPow.Apply(base, exp)
+}
+
+func (this *Func) Eval(m expr.IValuation) (float64, error) {
+	if this.Kind.Arity() != len(this.Args) {
+		return 0, fmt.Errorf("%s takes %d arguments, found %d", this.Kind, this.Kind.Arity(), len(this.Args))
+	}
+	args := make([]float64, len(this.Args))
+	for i, n := range this.Args {
+		var err error
+		if args[i], err = n.Eval(m); nil != err {
+			return 0, err
+		}
+	}
+	return this.Kind.Apply(args...)
+}
+
+func (this *Const) String() string {
+	return strconv.FormatFloat(this.Value, 'g', -1, 64)
+}
+
+func (this *VarRef) String() string {
+	return this.Name
+}
+
+func (this *Add) String() string {
+	if 0 == len(this.Terms) {
+		return "0"
+	}
+
+	buf := bytes.NewBufferString("")
+	for i, n := range this.Terms {
+		if 0 < i {
+			if abs, isNeg := negated(n); isNeg {
+				buf.WriteString(" - ")
+				buf.WriteString(wrap(abs, precMul))
+				continue
+			}
+			buf.WriteString(" + ")
+		}
+		buf.WriteString(wrap(n, precMul))
+	}
+	return buf.String()
+}
+
+func (this *Mul) String() string {
+	if 0 == len(this.Factors) {
+		return "1"
+	}
+
+	buf := bytes.NewBufferString("")
+	for i, n := range this.Factors {
+		if 0 < i {
+			buf.WriteString("*")
+			// a negative factor after the first would read as a subtraction
+			buf.WriteString(wrap(n, precPow))
+			continue
+		}
+		buf.WriteString(wrap(n, precUnary))
+	}
+	return buf.String()
+}
+
+func (this *Div) String() string {
+	return wrap(this.Num, precUnary) + "/" + wrap(this.Den, precPow)
+}
+
+func (this *Pow) String() string {
+	// right associative, x^y^z is x^(y^z)
+	return wrap(this.Base, precAtom) + "^" + wrap(this.Exp, precPow)
+}
+
+func (this *Func) String() string {
+	buf := bytes.NewBufferString(this.Kind.String())
+	buf.WriteString("(")
+	for i, n := range this.Args {
+		if 0 < i {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(n.String())
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func precOf(n Node) int {
+	switch o := n.(type) {
+	case *Const:
+		if 0 > o.Value || math.Signbit(o.Value) {
+			return precUnary
+		}
+	case *Add:
+		if 1 < len(o.Terms) {
+			return precAdd
+		}
+		if 1 == len(o.Terms) {
+			return precOf(o.Terms[0])
+		}
+	case *Mul:
+		if 1 < len(o.Factors) {
+			return precMul
+		}
+		if 1 == len(o.Factors) {
+			return precOf(o.Factors[0])
+		}
+	case *Div:
+		return precMul
+	case *Pow:
+		return precPow
+	}
+	return precAtom
+}
+
+// wrap returns n in parentheses if it binds looser than prec.
+func wrap(n Node, prec int) string {
+	if precOf(n) < prec {
+		return "(" + n.String() + ")"
+	}
+	return n.String()
+}
+
+// negated returns -n if n is a negative constant or a product or quotient
+// led by one, so that sums print "x - 2*y" rather than "x + -2*y".
+func negated(n Node) (Node, bool) {
+	switch o := n.(type) {
+	case *Const:
+		if 0 > o.Value {
+			return &Const{-o.Value}, true
+		}
+	case *Mul:
+		if 0 == len(o.Factors) {
+			break
+		}
+		lead, isNeg := negated(o.Factors[0])
+		if !isNeg {
+			break
+		}
+		if c, isConst := lead.(*Const); isConst && 1 == c.Value && 1 < len(o.Factors) {
+			return &Mul{o.Factors[1:]}, true
+		}
+		factors := append([]Node{lead}, o.Factors[1:]...)
+		return &Mul{factors}, true
+	case *Div:
+		if num, isNeg := negated(o.Num); isNeg {
+			return &Div{num, o.Den}, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,156 @@
+package ast
+
+import (
+	"math"
+	"testing"
+
+	"github.com/noypi/math0/expr"
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func x() Node          { return &VarRef{"x"} }
+func y() Node          { return &VarRef{"y"} }
+func c(f float64) Node { return &Const{f} }
+
+type _asttest struct {
+	n          Node
+	text       string
+	polynomial string // empty if ToPolynomial fails
+}
+
+var ttAst = []_asttest{
+	//0
+	_asttest{&Mul{[]Node{&Add{[]Node{x(), c(1)}}, &Add{[]Node{y(), c(-2)}}}},
+		"(x + 1)*(y - 2)", "-2 + -2(x) + 1(x*y) + 1(y)"},
+	//1
+	_asttest{&Div{x(), &Add{[]Node{y(), c(1)}}}, "x/(y + 1)", ""},
+	//2
+	_asttest{&Div{&Add{[]Node{x(), c(1)}}, c(2)}, "(x + 1)/2", "0.5 + 0.5(x)"},
+	//3
+	_asttest{&Pow{&Add{[]Node{x(), y()}}, c(2)}, "(x + y)^2", "2(x*y) + 1(x^2) + 1(y^2)"},
+	//4
+	_asttest{&Pow{x(), &Pow{y(), c(2)}}, "x^y^2", "1(pow(x, y^2))"},
+	//5
+	_asttest{&Add{[]Node{&Mul{[]Node{c(-3), x()}}, &Mul{[]Node{c(-1), y()}}, c(-4)}},
+		"-3*x - y - 4", "-4 + -3(x) + -1(y)"},
+	//6
+	_asttest{&Func{expr.FuncSin, []Node{&Mul{[]Node{x(), y()}}}}, "sin(x*y)", "1(sin(x*y))"},
+	//7
+	_asttest{&Pow{c(-2), c(2)}, "(-2)^2", "4"},
+	//8
+	_asttest{&Pow{&Add{[]Node{x(), c(1)}}, c(0.5)}, "(x + 1)^0.5", "1(pow(1 + x, 0.5))"},
+	//9
+	_asttest{&Pow{&Pow{x(), c(2)}, c(0.5)}, "(x^2)^0.5", "1(abs(x))"},
+	//10
+	_asttest{&Pow{&Mul{[]Node{c(4), x()}}, c(0.5)}, "(4*x)^0.5", "2(x^0.5)"},
+}
+
+func TestNode(t *testing.T) {
+	assert := assertpkg.New(t)
+	for i, tt := range ttAst {
+		assert.Equal(tt.text, tt.n.String(), "i=%d", i)
+
+		e, err := ToPolynomial(tt.n)
+		if "" == tt.polynomial {
+			assert.NotNil(err, "i=%d", i)
+			continue
+		}
+		if !assert.Nil(err, "i=%d", i) {
+			continue
+		}
+		assert.Equal(tt.polynomial, e.String(), "i=%d", i)
+
+		// the printed tree parses back to the same polynomial
+		parsed, err := expr.ParseExpr(tt.text)
+		if assert.Nil(err, "i=%d", i) {
+			assert.Equal(tt.polynomial, parsed.String(), "i=%d", i)
+		}
+	}
+}
+
+func TestToPolynomial_Errors(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// the errors of expr.Pow pass through
+	for _, tt := range []struct {
+		n        Node
+		expected string
+	}{
+		{&Pow{c(0), c(-1)}, "division by zero"},
+		{&Div{x(), &Add{[]Node{x(), c(-1)}}}, "not a sum of terms"},
+		{&Div{x(), &Add{[]Node{x(), &Mul{[]Node{c(-1), x()}}}}}, "by zero"},
+	} {
+		_, err := ToPolynomial(tt.n)
+		if assert.NotNil(err, "%s", tt.n) {
+			assert.Contains(err.Error(), tt.expected, "%s", tt.n)
+		}
+	}
+}
+
+func TestNode_Eval(t *testing.T) {
+	assert := assertpkg.New(t)
+	m := expr.Valuation{"x": 3, "y": 0.5}
+
+	for i, tt := range ttAst {
+		v, err := tt.n.Eval(m)
+		if !assert.Nil(err, "i=%d", i) {
+			continue
+		}
+		e, err := ToPolynomial(tt.n)
+		if nil != err {
+			continue
+		}
+		expected, err := expr.ValueOfExpr(e, m)
+		assert.Nil(err, "i=%d", i)
+		assert.InDelta(expected, v, 1e-12, "i=%d", i)
+	}
+
+	v, err := ttAst[1].n.Eval(m)
+	assert.Nil(err)
+	assert.InDelta(2.0, v, 1e-12)
+
+	_, err = ttAst[1].n.Eval(expr.Valuation{"x": 3, "y": -1})
+	assert.NotNil(err)
+	_, err = x().Eval(expr.Valuation{})
+	assert.NotNil(err)
+	_, err = (&Func{expr.FuncLog, []Node{c(-1)}}).Eval(m)
+	assert.NotNil(err)
+}
+
+func TestFromExpression(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for _, s := range []string{"3x^2 - 2x*y + 5", "-x", "0", "7", "x^-1*y^0.5", "2sin(x)^2 + exp(-x)"} {
+		e, err := expr.ParseExpr(s)
+		if !assert.Nil(err, s) {
+			continue
+		}
+
+		n, err := FromExpression(e)
+		if !assert.Nil(err, s) {
+			continue
+		}
+		back, err := ToPolynomial(n)
+		if assert.Nil(err, s) {
+			assert.Equal(e.String(), back.String(), s)
+		}
+		m := expr.Valuation{"x": 1.5, "y": 2}
+		v, err := n.Eval(m)
+		assert.Nil(err, s)
+		expected, _ := expr.ValueOfExpr(e, m)
+		assert.False(math.IsNaN(v), s)
+		assert.InDelta(expected, v, 1e-12, s)
+	}
+
+	n, err := FromExpression(expr.NewExpr(expr.MustTerms("3x^2", "-2x*y", "5")...))
+	if assert.Nil(err) {
+		assert.Equal("5 - 2*x*y + 3*x^2", n.String())
+	}
+
+	// trees have no complex constants
+	ix := expr.NewExpr(expr.NewComplexTerm(2i, expr.NewVar("x")), expr.NewTerm(1))
+	for _, e := range []expr.IExpression{ix, expr.FuncExpr(expr.FuncSin, 1, ix)} {
+		_, err = FromExpression(e)
+		assert.NotNil(err, "%s", e)
+	}
+}
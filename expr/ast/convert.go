@@ -0,0 +1,180 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/noypi/math0"
+	"github.com/noypi/math0/expr"
+)
+
+// ToPolynomial expands n into a sum of terms built with the
+// expr.EqnBuilder_* constructors. Division is only possible by a single
+// nonzero term, otherwise the error is of type expr.ErrNotPolynomial, or
+// expr.ErrDivisionByZero. Non-integral powers are those of expr.PowReal.
+func ToPolynomial(n Node) (e expr.IExpression, err error) {
+	switch o := n.(type) {
+	case *Const:
+		e = constExpr(o.Value)
+
+	case *VarRef:
+		v := expr.EqnBuilder_VarConstructor(o.Name, 1.0)
+		e = expr.EqnBuilder_ExprConstructor(expr.EqnBuilder_TermConstructor(1.0, v))
+
+	case *Add:
+		e = constExpr(0)
+		for _, t := range o.Terms {
+			var te expr.IExpression
+			if te, err = ToPolynomial(t); nil != err {
+				return nil, err
+			}
+			e = expr.Add(e, te)
+		}
+
+	case *Mul:
+		e = constExpr(1)
+		for _, f := range o.Factors {
+			var fe expr.IExpression
+			if fe, err = ToPolynomial(f); nil != err {
+				return nil, err
+			}
+			e = expr.Mul(e, fe)
+		}
+
+	case *Div:
+		var num, den expr.IExpression
+		if num, err = ToPolynomial(o.Num); nil != err {
+			return
+		}
+		if den, err = ToPolynomial(o.Den); nil != err {
+			return
+		}
+		if 0 == len(den.Terms()) {
+			return nil, expr.ErrDivisionByZero(fmt.Errorf("division of %v by zero", o.Num))
+		}
+		var inverse expr.IExpression
+		if inverse, err = expr.Pow(den, -1); nil != err {
+			return nil, err
+		}
+		e = expr.Mul(num, inverse)
+
+	case *Pow:
+		var base, exp expr.IExpression
+		if base, err = ToPolynomial(o.Base); nil != err {
+			return
+		}
+		if exp, err = ToPolynomial(o.Exp); nil != err {
+			return
+		}
+		k, isConst := expr.ConstantOf(exp)
+		if !isConst {
+			e = expr.FuncExpr(expr.FuncPow, 1, base, exp)
+			break
+		}
+		e, err = expr.PowReal(base, k)
+
+	case *Func:
+		if o.Kind.Arity() != len(o.Args) {
+			return nil, fmt.Errorf("%s takes %d arguments, found %d", o.Kind, o.Kind.Arity(), len(o.Args))
+		}
+		args := make([]expr.IExpression, len(o.Args))
+		values := make([]float64, len(o.Args))
+		bConst := true
+		for i, arg := range o.Args {
+			if args[i], err = ToPolynomial(arg); nil != err {
+				return
+			}
+			var isConst bool
+			values[i], isConst = expr.ConstantOf(args[i])
+			bConst = bConst && isConst
+		}
+		if !bConst {
+			e = expr.FuncExpr(o.Kind, 1, args...)
+			break
+		}
+		var c float64
+		if c, err = o.Kind.Apply(values...); nil != err {
+			return nil, err
+		}
+		e = constExpr(c)
+
+	default:
+		err = fmt.Errorf("unknown node %T", n)
+	}
+	return
+}
+
+// FromExpression returns e as a tree: a sum of products of an optional
+// coefficient and the factors of each term. Trees have real constants
+// only, so a complex coefficient, also in a function argument, is an
+// expr.ErrDomain.
+func FromExpression(e expr.IExpression) (Node, error) {
+	if err := expr.CheckReal(e); nil != err {
+		return nil, err
+	}
+	terms := e.Terms()
+	if 0 == len(terms) {
+		return &Const{0}, nil
+	}
+
+	nodes := make([]Node, 0, len(terms))
+	for _, term := range terms {
+		n, err := fromTerm(term)
+		if nil != err {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if 1 == len(nodes) {
+		return nodes[0], nil
+	}
+	return &Add{nodes}, nil
+}
+
+func fromTerm(term expr.ITerm) (Node, error) {
+	vs := term.Vars()
+	if 0 == len(vs) {
+		return &Const{term.C()}, nil
+	}
+
+	factors := []Node{}
+	if !math0.IsApproxEqual(term.C(), 1.0) {
+		factors = append(factors, &Const{term.C()})
+	}
+	for _, v := range vs {
+		n, err := fromVar(v)
+		if nil != err {
+			return nil, err
+		}
+		factors = append(factors, n)
+	}
+	if 1 == len(factors) {
+		return factors[0], nil
+	}
+	return &Mul{factors}, nil
+}
+
+func fromVar(v expr.IVariable) (Node, error) {
+	var n Node = &VarRef{v.Name()}
+	if fn, isFunc := v.(expr.IFunction); isFunc {
+		args := make([]Node, len(fn.Args()))
+		for i, arg := range fn.Args() {
+			var err error
+			if args[i], err = FromExpression(arg); nil != err {
+				return nil, err
+			}
+		}
+		n = &Func{fn.Kind(), args}
+	}
+
+	if math0.IsApproxEqual(v.Power(), 1.0) {
+		return n, nil
+	}
+	return &Pow{n, &Const{v.Power()}}, nil
+}
+
+func constExpr(c float64) expr.IExpression {
+	if 0 == c {
+		return expr.EqnBuilder_ExprConstructor()
+	}
+	return expr.EqnBuilder_ExprConstructor(expr.EqnBuilder_TermConstructor(c))
+}
@@ -12,19 +12,22 @@ const (
 func (this BasicOperation) Apply(expr IExpression, c float64) {
 	switch this {
 	case Addition:
-		fallthrough
-	case Subtraction:
 		expr.AddTerm(NewTerm(c))
+	case Subtraction:
+		expr.AddTerm(NewTerm(-c))
 
 	case Multiplication:
 		fallthrough
 	case Division:
 		expr.EachTerm(func(term ITerm) bool {
-			switch this {
-			case Multiplication:
-				term.SetC(term.C() * c)
-			case Division:
-				term.SetC(term.C() / c)
+			k := c
+			if Division == this {
+				k = 1 / c
+			}
+			if ct, isComplex := term.(IComplexTerm); isComplex {
+				ct.SetCC(ct.CC() * complex(k, 0))
+			} else {
+				term.SetC(term.C() * k)
 			}
 
 			// get all terms
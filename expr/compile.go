@@ -0,0 +1,143 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+)
+
+// Compile flattens e into an evaluator taking the variables in varOrder,
+// e.g. f(x) with x[i] the value of varOrder[i]. The evaluator does not
+// allocate and uses repeated multiplication for integral powers. It must
+// be called with len(varOrder) values.
+func Compile(e IExpression, varOrder []string) (func(x []float64) float64, error) {
+	index := make(map[string]int, len(varOrder))
+	for i, name := range varOrder {
+		index[name] = i
+	}
+
+	var c _Compiled
+	var err error
+	e.EachTerm(func(term ITerm) bool {
+		if isComplexCoeff(coeffOf(term)) {
+			err = ErrDomain(fmt.Errorf("%s has a complex coefficient", term))
+			return false
+		}
+		c.coeffs = append(c.coeffs, term.C())
+		for _, v := range term.Vars() {
+			i, has := index[v.Name()]
+			if fn, isFunc := v.(IFunction); isFunc {
+				var eval func(x []float64) float64
+				if eval, err = compileFunc(fn, varOrder); nil != err {
+					return false
+				}
+				c.funcs = append(c.funcs, eval)
+				i = -len(c.funcs)
+			} else if !has {
+				err = ErrNoValuationForVar(fmt.Errorf("var=%s is not in the variable order", v.Name()))
+				return false
+			}
+			c.vars = append(c.vars, i)
+			c.powers = append(c.powers, v.Power())
+			if isIntegral(v.Power()) {
+				c.ipowers = append(c.ipowers, int(math.Round(v.Power())))
+			} else {
+				c.ipowers = append(c.ipowers, 0)
+			}
+		}
+		c.ends = append(c.ends, len(c.vars))
+		return true
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	return c.Eval, nil
+}
+
+type _Compiled struct {
+	coeffs  []float64
+	ends    []int // end of each term's factors in vars
+	vars    []int // index into x, or -1-i for funcs[i]
+	powers  []float64
+	ipowers []int // zero if the power is not integral
+	funcs   []func(x []float64) float64
+}
+
+func (this *_Compiled) Eval(x []float64) (value float64) {
+	j := 0
+	for i, c := range this.coeffs {
+		for ; j < this.ends[i]; j++ {
+			var f float64
+			if k := this.vars[j]; 0 <= k {
+				f = x[k]
+			} else {
+				f = this.funcs[-1-k](x)
+			}
+
+			if n := this.ipowers[j]; 0 != n {
+				c *= powInt(f, n)
+			} else {
+				c *= math.Pow(f, this.powers[j])
+			}
+		}
+		value += c
+	}
+	return
+}
+
+// compileFunc evaluates fn without its power; domain errors yield NaN.
+func compileFunc(fn IFunction, varOrder []string) (func(x []float64) float64, error) {
+	kind := fn.Kind()
+	evals := make([]func(x []float64) float64, len(fn.Args()))
+	for i, arg := range fn.Args() {
+		var err error
+		if evals[i], err = Compile(arg, varOrder); nil != err {
+			return nil, err
+		}
+	}
+
+	if 1 == len(evals) {
+		u := evals[0]
+		return func(x []float64) float64 {
+			f, err := kind.Apply(u(x))
+			if nil != err {
+				return math.NaN()
+			}
+			return f
+		}, nil
+	}
+	return func(x []float64) float64 {
+		var args [2]float64
+		for i, eval := range evals {
+			args[i] = eval(x)
+		}
+		f, err := kind.Apply(args[:len(evals)]...)
+		if nil != err {
+			return math.NaN()
+		}
+		return f
+	}, nil
+}
+
+func powInt(f float64, n int) float64 {
+	switch n {
+	case 1:
+		return f
+	case 2:
+		return f * f
+	case 3:
+		return f * f * f
+	}
+
+	if 0 > n {
+		return 1 / powInt(f, -n)
+	}
+	out := 1.0
+	for ; 0 < n; n >>= 1 {
+		if 1 == n&1 {
+			out *= f
+		}
+		f *= f
+	}
+	return out
+}
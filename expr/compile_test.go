@@ -0,0 +1,50 @@
+package expr
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+const compileTestExpr = "3x^2*y + 2x*y*z - 5z^3 + x^7 + 0.5y^1.5 + 11"
+
+func TestCompile(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e := mustParse(compileTestExpr)
+	f, err := Compile(e, []string{"x", "y", "z"})
+	assert.Nil(err)
+
+	for _, m := range []Valuation{
+		{"x": 1, "y": 2, "z": 3},
+		{"x": -1.5, "y": 0.25, "z": 0},
+		{"x": 0, "y": 0, "z": 0},
+	} {
+		expected, err := ValueOfExpr(e, m)
+		assert.Nil(err)
+		assert.InDelta(expected, f([]float64{m["x"], m["y"], m["z"]}), 1e-9, "%v", m)
+	}
+
+	_, err = Compile(e, []string{"x", "y"})
+	assert.NotNil(err)
+}
+
+func BenchmarkValueOfExpr(b *testing.B) {
+	e := mustParse(compileTestExpr)
+	m := Valuation{"x": 1.25, "y": 2, "z": -0.5}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ValueOfExpr(e, m)
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	f, _ := Compile(mustParse(compileTestExpr), []string{"x", "y", "z"})
+	x := []float64{1.25, 2, -0.5}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f(x)
+	}
+}
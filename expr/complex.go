@@ -0,0 +1,210 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strconv"
+
+	"github.com/noypi/math0"
+)
+
+// IComplexTerm is a term with a complex coefficient, e.g. (1+2i)(s^2). C()
+// and SetC() see the real part only.
+type IComplexTerm interface {
+	ITerm
+	CC() complex128
+	SetCC(c complex128)
+}
+
+// ComplexValuation assigns complex values to variables.
+type ComplexValuation map[string]complex128
+
+type _ComplexTerm struct {
+	_Term
+	imag float64
+}
+
+func NewComplexTerm(c complex128, vs ...IVariable) IComplexTerm {
+	o := &_ComplexTerm{imag: imag(c)}
+	o.c = real(c)
+	o.SetVars(vs...)
+	return o
+}
+
+func (this ComplexValuation) Get(varname string) (c complex128, has bool) {
+	c, has = this[varname]
+	return
+}
+
+func (this _ComplexTerm) CC() complex128 {
+	return complex(this.c, this.imag)
+}
+
+func (this *_ComplexTerm) SetCC(c complex128) {
+	this.c, this.imag = real(c), imag(c)
+}
+
+// SetC sets a real coefficient, the imaginary part is cleared.
+func (this *_ComplexTerm) SetC(c float64) {
+	this.c, this.imag = c, 0
+}
+
+func (this _ComplexTerm) Clone() ITerm {
+	return NewComplexTerm(this.CC(), this.vars...)
+}
+
+func (this _ComplexTerm) String() string {
+	c := strconv.FormatComplex(this.CC(), 'f', -1, 128)
+	if 0 == len(this.vars) {
+		return c
+	}
+	return fmt.Sprintf("%s(%s)", c, this.vars.String())
+}
+
+// coeffOf is the coefficient of term, complex or not.
+func coeffOf(term ITerm) complex128 {
+	if ct, isComplex := term.(IComplexTerm); isComplex {
+		return ct.CC()
+	}
+	return complex(term.C(), 0)
+}
+
+func isZeroCoeff(c complex128) bool {
+	return math0.IsApproxEqual(real(c), 0.0) && math0.IsApproxEqual(imag(c), 0.0)
+}
+
+func isComplexCoeff(c complex128) bool {
+	return !math0.IsApproxEqual(imag(c), 0.0)
+}
+
+// CheckReal returns an ErrDomain if a term of e has an imaginary part, for
+// operations that work with real coefficients only.
+func CheckReal(e IExpression) (err error) {
+	e.EachTerm(func(term ITerm) bool {
+		if isComplexCoeff(coeffOf(term)) {
+			err = ErrDomain(fmt.Errorf("%s has a complex coefficient", term))
+		}
+		return nil == err
+	})
+	return
+}
+
+// newTermC returns c*vs, built with EqnBuilder_TermConstructor unless c has
+// an imaginary part.
+func newTermC(c complex128, vs ...IVariable) ITerm {
+	if isComplexCoeff(c) {
+		return NewComplexTerm(c, vs...)
+	}
+	return EqnBuilder_TermConstructor(real(c), vs...)
+}
+
+// withCoeff sets the coefficient of term to c, in place if term is of the
+// right kind for c, else it returns a copy of the right kind.
+func withCoeff(term ITerm, c complex128) ITerm {
+	ct, isComplex := term.(IComplexTerm)
+	switch {
+	case isComplex && isComplexCoeff(c):
+		ct.SetCC(c)
+		return ct
+	case isComplex || isComplexCoeff(c):
+		return newTermC(c, term.Vars()...)
+	}
+	term.SetC(real(c))
+	return term
+}
+
+// ValueOfExprComplex evaluates e with complex values. Powers and functions
+// take their principal values, so sqrt(-1) is i.
+func ValueOfExprComplex(e IExpression, m ComplexValuation) (value complex128, err error) {
+	e.EachTerm(func(term ITerm) bool {
+		termValue := coeffOf(term)
+		for _, v := range term.Vars() {
+			if math0.IsApproxEqual(v.Power(), 0.0) {
+				continue
+			}
+			var z complex128
+			if z, err = complexOfFactor(v, m); nil != err {
+				return false
+			}
+			if 0 == z && 0 > v.Power() {
+				err = ErrDivisionByZero(fmt.Errorf("%s is undefined at %s=0", v, v.Name()))
+				return false
+			}
+			termValue *= complexPow(z, v.Power())
+		}
+		value += termValue
+		return true
+	})
+
+	return
+}
+
+func complexOfFactor(v IVariable, m ComplexValuation) (complex128, error) {
+	fn, isFunc := v.(IFunction)
+	if !isFunc {
+		z, has := m.Get(v.Name())
+		if !has {
+			return 0, ErrNoValuationForVar(fmt.Errorf("var=%s has no valuation", v.Name()))
+		}
+		return z, nil
+	}
+
+	args := make([]complex128, len(fn.Args()))
+	for i, arg := range fn.Args() {
+		var err error
+		if args[i], err = ValueOfExprComplex(arg, m); nil != err {
+			return 0, err
+		}
+	}
+
+	u := args[0]
+	switch fn.Kind() {
+	case FuncSin:
+		return cmplx.Sin(u), nil
+	case FuncCos:
+		return cmplx.Cos(u), nil
+	case FuncTan:
+		return cmplx.Tan(u), nil
+	case FuncExp:
+		return cmplx.Exp(u), nil
+	case FuncLog:
+		if 0 == u {
+			return 0, ErrDomain(fmt.Errorf("log(0) is undefined"))
+		}
+		return cmplx.Log(u), nil
+	case FuncSqrt:
+		return cmplx.Sqrt(u), nil
+	case FuncAbs:
+		return complex(cmplx.Abs(u), 0), nil
+	case FuncPow:
+		if 0 == u && 0 > real(args[1]) {
+			return 0, ErrDivisionByZero(fmt.Errorf("pow(0, %v) is undefined", args[1]))
+		}
+		return cmplx.Pow(u, args[1]), nil
+	}
+	return 0, fmt.Errorf("unknown function %d", int(fn.Kind()))
+}
+
+// complexPow uses repeated multiplication for integral powers, which keeps
+// e.g. i^2 exactly -1.
+func complexPow(z complex128, p float64) complex128 {
+	if !isIntegral(p) {
+		return cmplx.Pow(z, complex(p, 0))
+	}
+
+	n := int(math.Round(p))
+	if 0 > n {
+		z, n = 1/z, -n
+	}
+	out := complex(1, 0)
+	for ; 0 < n; n >>= 1 {
+		if 1 == n&1 {
+			out *= z
+		}
+		if 1 < n {
+			z *= z
+		}
+	}
+	return out
+}
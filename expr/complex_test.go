@@ -0,0 +1,124 @@
+package expr
+
+import (
+	"math/cmplx"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestComplexTerm(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	s := MustVars("s")
+	sPlusI := NewExpr(NewTerm(1, s...), NewComplexTerm(1i))
+	sMinusI := NewExpr(NewTerm(1, s...), NewComplexTerm(-1i))
+	assert.Equal("(0+1i) + 1(s)", sPlusI.String())
+
+	// (s + i)(s - i) = s^2 + 1, the imaginary terms cancel
+	assert.Equal("1 + 1(s^2)", Mul(sPlusI, sMinusI).String())
+	assert.Equal("(0+2i) + (2+2i)(s) + 1(s^2)", mustPow(NewExpr(NewTerm(1, s...), NewComplexTerm(1+1i)), 2).String())
+	assert.Equal("(0-2i)", Sub(sMinusI, sPlusI).String())
+	assert.Equal("(0-0.5i)(s^-1)", mustPow(NewExpr(NewComplexTerm(2i, s...)), -1).String())
+
+	// coefficients of equal keys combine, a real sum becomes a real term
+	e := NewExpr(NewComplexTerm(1+2i, s...), NewComplexTerm(3-2i, s...), NewTerm(-4, s...), NewComplexTerm(1i))
+	assert.Equal("(0+1i)", e.String())
+	e = NewExpr(NewComplexTerm(1+2i, s...), NewComplexTerm(3-2i, s...))
+	assert.Equal("4(s)", e.String())
+	_, isComplex := e.TermAt(0).(IComplexTerm)
+	assert.False(isComplex)
+
+	e = NewExpr(NewComplexTerm(2+4i, s...))
+	Division.Apply(e, 2)
+	assert.Equal("(1+2i)(s)", e.String())
+
+	assert.Equal("(0+3i)(s^2)", Derivative(NewExpr(NewComplexTerm(1i, MustVars("s^3")...)), "s").String())
+	assert.Equal("(1+2i)(s)", e.Clone().String())
+	assert.Equal("(1+2i)(s^2)", Substitute(e, "s", mustParse("s^2")).String())
+}
+
+func TestValueOfExprComplex(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// H(s) = 1/(s + 1) at s = i is (1 - i)/2
+	h := NewExpr(NewTerm(1, NewFuncN(FuncPow, 1, mustParse("s + 1"), mustParse("-1"))))
+	v, err := ValueOfExprComplex(h, ComplexValuation{"s": 1i})
+	assert.Nil(err)
+	assert.InDelta(0, cmplx.Abs(v-(0.5-0.5i)), 1e-12)
+
+	e := NewExpr(NewComplexTerm(2+1i, MustVars("s^2")...), NewTerm(3, MustVars("s")...), NewTerm(1))
+	v, err = ValueOfExprComplex(e, ComplexValuation{"s": 1i})
+	assert.Nil(err)
+	// (2+i)(-1) + 3i + 1
+	assert.Equal(complex(-1, 2), v)
+
+	v, err = ValueOfExprComplex(mustParse("sqrt(x) + exp(y)"), ComplexValuation{"x": -4, "y": 1i * 3.141592653589793})
+	assert.Nil(err)
+	assert.InDelta(0, cmplx.Abs(v-(-1+2i)), 1e-12)
+
+	_, err = ValueOfExprComplex(mustParse("s^-1"), ComplexValuation{"s": 0})
+	assert.NotNil(err)
+	_, err = ValueOfExprComplex(mustParse("s"), ComplexValuation{})
+	assert.NotNil(err)
+
+	// the float evaluators refuse complex coefficients
+	_, err = ValueOfExpr(e, Valuation{"s": 1})
+	assert.NotNil(err)
+	_, err = Compile(e, []string{"s"})
+	assert.NotNil(err)
+}
+
+func TestComplexTerm_Polynomial(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	x, y := MustVars("x"), MustVars("y")
+	e := NewExpr(NewComplexTerm(1+1i), NewComplexTerm(2i, append(x, y...)...))
+	assert.Equal("(1+1i) + (0+6i)(x)", mustPartialEval(e, Valuation{"y": 3}).String())
+	assert.Equal("(1+7i)", mustPartialEval(e, Valuation{"x": 1, "y": 3}).String())
+
+	// (2i x^2 + 2i) / (x + 1) = 2i x - 2i, remainder 4i
+	p := NewExpr(NewComplexTerm(2i, MustVars("x^2")...), NewComplexTerm(2i))
+	q, r, err := DivMod(p, mustParse("x + 1"), "x")
+	if assert.Nil(err) {
+		assert.Equal("(-0-2i) + (0+2i)(x)", q.String())
+		assert.Equal("(0+4i)", r.String())
+	}
+	q, r, err = DivMod(p, NewExpr(NewComplexTerm(1i, x...), NewTerm(1)), "x")
+	if assert.Nil(err) {
+		assert.Equal("(0+2i) + 2(x)", q.String())
+		assert.Equal("0", r.String())
+	}
+
+	// not supported with complex coefficients
+	_, err = GCD(p, mustParse("(x + 1)^2"))
+	assert.NotNil(err)
+	_, err = GCD(mustParse("x^2 - 1"), p)
+	assert.NotNil(err)
+	_, err = Roots(p, "x")
+	assert.NotNil(err)
+	_, err = RealRoots(p, "x")
+	assert.NotNil(err)
+	_, err = ToRatExpr(NewExpr(NewTerm(1), NewComplexTerm(2i, x...)))
+	assert.NotNil(err)
+}
+
+func TestComplexTerm_Constant(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	_, isConst := ConstantOf(NewExpr(NewComplexTerm(1 + 2i)))
+	assert.False(isConst)
+	c, isConst := ConstantOf(NewExpr(NewComplexTerm(3 + 0i)))
+	assert.True(isConst)
+	assert.Equal(3.0, c)
+
+	// the imaginary part is part of a function's name
+	ix := NewExpr(NewComplexTerm(2i, MustVars("x")...), NewComplexTerm(1i))
+	assert.Equal("sin((0+1i) + (0+2i)*x)", NewFunc(FuncSin, ix).Name())
+	assert.NotEqual(NewFunc(FuncSin, ix).Name(), NewFunc(FuncSin, NewExpr()).Name())
+	assert.Equal("cos(2 + -x)", NewFunc(FuncCos, mustParse("2 - x")).Name())
+
+	// sin(2i) is not folded as sin(0)
+	e := Mul(FuncExpr(FuncSin, 1, NewExpr(NewComplexTerm(2i, MustVars("x")...))), mustParse("y"))
+	assert.Equal("1(sin((0+2i))*y)", mustPartialEval(e, Valuation{"x": 1}).String())
+}
@@ -0,0 +1,42 @@
+package expr
+
+// Derivative returns d(e)/d(varname), e.g. d/dx of c*x^n*y^m is
+// c*n*x^(n-1)*y^m. Function factors are differentiated by the chain rule.
+func Derivative(e IExpression, varname string) IExpression {
+	out := newExprOf()
+	e.EachTerm(func(term ITerm) bool {
+		out = Add(out, derivativeOfTerm(term, varname))
+		return true
+	})
+	return out
+}
+
+// Gradient returns the partial derivatives of e, in the order of vars.
+func Gradient(e IExpression, vars []string) []IExpression {
+	out := make([]IExpression, len(vars))
+	for i, name := range vars {
+		out[i] = Derivative(e, name)
+	}
+	return out
+}
+
+// derivativeOfTerm applies the product rule over the factors of term.
+func derivativeOfTerm(term ITerm, varname string) IExpression {
+	out := newExprOf()
+	vs := term.Vars()
+	for i, v := range vs {
+		var d IExpression
+		if fn, isFunc := v.(IFunction); isFunc && dependsOn(v, varname) {
+			d = derivativeOfFunc(fn, varname)
+		} else if !isFunc && v.Name() == varname {
+			d = newExprOf(NewTerm(v.Power(), v.AddPower(-1)))
+		} else {
+			continue
+		}
+
+		rest := make(VariableList, 0, len(vs)-1)
+		rest = append(append(rest, vs[:i]...), vs[i+1:]...)
+		out = Add(out, Mul(newExprOf(newTermC(coeffOf(term), rest...)), d))
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package expr
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+type _derivativetest struct {
+	e        string
+	varname  string
+	expected string
+}
+
+var ttDerivative = []_derivativetest{
+	//0
+	_derivativetest{"3x^2 + 2x*y - 5", "x", "6(x) + 2(y)"},
+	//1
+	_derivativetest{"3x^2 + 2x*y - 5", "y", "2(x)"},
+	//2
+	_derivativetest{"3x^2 + 2x*y - 5", "z", "0"},
+	//3
+	_derivativetest{"x^3*y^2 + x", "x", "1 + 3(x^2*y^2)"},
+	//4
+	_derivativetest{"7", "x", "0"},
+}
+
+func TestDerivative(t *testing.T) {
+	assert := assertpkg.New(t)
+	for i, tt := range ttDerivative {
+		e, err := ParseExpr(tt.e)
+		assert.Nil(err, "i=%d", i)
+		assert.Equal(tt.expected, Derivative(e, tt.varname).String(), "i=%d", i)
+	}
+}
+
+func TestGradient(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e, err := ParseExpr("x^2*y + y^3")
+	assert.Nil(err)
+	grad := Gradient(e, []string{"x", "y"})
+	assert.Equal(2, len(grad))
+	assert.Equal("2(x*y)", grad[0].String())
+	assert.Equal("1(x^2) + 3(y^2)", grad[1].String())
+}
@@ -1,10 +1,8 @@
 package expr
 
 import (
-	"fmt"
+	"strconv"
 	"strings"
-
-	"github.com/noypi/math0"
 )
 
 var (
@@ -13,7 +11,7 @@ var (
 	EqnBuilder_VarConstructor  = NewVarN
 )
 
-// Eqn(Terms("3x", "-4y"))(NEQ)(Terms("5"))
+// Eqn(MustTerms("3x", "-4y"))(NEQ)(MustTerms("5"))
 func Eqn(left TermList) func(Relation) func(right TermList) IEquation {
 	eqn := Equation(EqnBuilder_ExprConstructor(left...), EQ, nil)
 	return func(rel Relation) func(right TermList) IEquation {
@@ -28,37 +26,90 @@ func Eqn(left TermList) func(Relation) func(right TermList) IEquation {
 	}
 }
 
-// Terms("2x^2*y^3", "2y")
-func Terms(terms ...string) TermList {
-	var ls TermList
+// MustTerms is Terms for literals written in code; like
+// regexp.MustCompile, it panics with a *ParseError on malformed input, so
+// that Eqn(MustTerms(...))(rel)(MustTerms(...)) stays a single expression.
+func MustTerms(terms ...string) TermList {
+	ls, err := Terms(terms...)
+	if nil != err {
+		panic(err)
+	}
+	return ls
+}
+
+// MustVars is Vars for literals written in code, panicking on malformed
+// input.
+func MustVars(vars ...string) VariableList {
+	vs, err := Vars(vars...)
+	if nil != err {
+		panic(err)
+	}
+	return vs
+}
+
+// Terms("2x^2*y^3", "2y") parses each string with ParseExpr and collects
+// the terms, so "x + 3" yields two terms. Blank strings are skipped.
+func Terms(terms ...string) (ls TermList, err error) {
 	for _, s := range terms {
-		var f float64
-		var vars string
-		fmt.Sscanf(s, "%f%s", &f, &vars)
-		if 0.0 == f && 0 == len(vars) && 0 < len(s) {
-			f = 1.0
-			vars = s
+		if 0 == len(strings.TrimSpace(s)) {
+			continue
+		}
+		var e IExpression
+		if e, err = ParseExpr(s); nil != err {
+			return nil, err
 		}
-		vs := []IVariable(Vars(strings.Split(strings.Trim(vars, "()"), "*")...))
-		ls = append(ls, EqnBuilder_TermConstructor(f, vs...))
+		ls = append(ls, e.Terms()...)
 	}
-	return ls
+	return
 }
 
-// Vars("x^2", "x", "y", "y^2")
-func Vars(vars ...string) VariableList {
-	var vs VariableList
+// Vars("x^2", "x", "y", "y^2") parses variables of the form "x" or "x^2".
+// Blank strings are skipped.
+func Vars(vars ...string) (vs VariableList, err error) {
 	for _, s := range vars {
-		if 0 == len(s) {
+		if 0 == len(strings.TrimSpace(s)) {
 			continue
 		}
-		var x string
-		var power float64 = 1.0
-		fmt.Sscanf(s, "%s^%f", &x, &power)
-		if math0.IsApproxEqual(0.0, power) {
-			power = 0.0
+		var v IVariable
+		if v, err = parseVar(s); nil != err {
+			return nil, err
 		}
-		vs = append(vs, EqnBuilder_VarConstructor(x, power))
+		vs = append(vs, v)
 	}
-	return vs
+	return
+}
+
+func parseVar(s string) (IVariable, error) {
+	p := newParser(s)
+	name := p.tok
+	if err := p.expect(tokIdent); nil != err {
+		return nil, err
+	}
+	p.next()
+
+	power := 1.0
+	if tokCaret == p.tok.kind {
+		p.next()
+		sign := 1.0
+		if tokMinus == p.tok.kind || tokPlus == p.tok.kind {
+			if tokMinus == p.tok.kind {
+				sign = -1.0
+			}
+			p.next()
+		}
+		if err := p.expect(tokNumber); nil != err {
+			return nil, err
+		}
+		f, err := strconv.ParseFloat(p.tok.text, 64)
+		if nil != err {
+			return nil, p.errorf(p.tok, "invalid number %s", p.tok)
+		}
+		power = sign * f
+		p.next()
+	}
+
+	if err := p.expect(tokEOF); nil != err {
+		return nil, err
+	}
+	return EqnBuilder_VarConstructor(name.text, power), nil
 }
@@ -2,6 +2,7 @@ package expr
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	assertpkg "github.com/stretchr/testify/assert"
@@ -21,39 +22,39 @@ type _eqnbuildtest struct {
 var ttEqnBuilder = []_eqnbuildtest{
 	//0
 	_eqnbuildtest{
-		left:     Terms("x"),
-		right:    Terms("5y"),
+		left:     MustTerms("x"),
+		right:    MustTerms("5y"),
 		relation: EQ,
 		expected: "1(x) == 5(y)",
 	},
 
 	//1
 	_eqnbuildtest{
-		left:     Terms("3x", "-4y"),
-		right:    Terms("5"),
+		left:     MustTerms("3x", "-4y"),
+		right:    MustTerms("5"),
 		relation: NEQ,
 		expected: "3(x) + -4(y) != 5",
 	},
 
 	//2
 	_eqnbuildtest{
-		left:     Terms("3x", "-4y"),
-		right:    Terms("5"),
+		left:     MustTerms("3x", "-4y"),
+		right:    MustTerms("5"),
 		relation: GEQ,
 		expected: "3(x) + -4(y) >= 5",
 	},
 
 	//3
 	_eqnbuildtest{
-		left:     Terms("3(x)", "-4(y)"),
-		right:    Terms("55z", "6(x^3*y^4)"),
+		left:     MustTerms("3(x)", "-4(y)"),
+		right:    MustTerms("55z", "6(x^3*y^4)"),
 		relation: LEQ,
 		expected: "3(x) + -4(y) <= 6(x^3*y^4) + 55(z)",
 	},
 
 	//4
 	_eqnbuildtest{
-		left:     Terms("3(x)", "-4(y)"),
+		left:     MustTerms("3(x)", "-4(y)"),
 		right:    nil,
 		relation: Lesser,
 		expected: "3(x) + -4(y) < 0",
@@ -61,8 +62,8 @@ var ttEqnBuilder = []_eqnbuildtest{
 
 	//5
 	_eqnbuildtest{
-		left:     Terms("3(x)", "-4(y)"),
-		right:    Terms(""),
+		left:     MustTerms("3(x)", "-4(y)"),
+		right:    MustTerms(""),
 		relation: Greater,
 		expected: "3(x) + -4(y) > 0",
 	},
@@ -76,9 +77,57 @@ func TestEqnBuilder(t *testing.T) {
 	}
 }
 
+var ttTerms = []_parsetest{
+	//0
+	_parsetest{"3x", "3(x)"},
+	//1
+	_parsetest{"-4y, 5", "5 + -4(y)"},
+	//2
+	_parsetest{"2x^2*y^3, 2y", "2(x^2*y^3) + 2(y)"},
+	//3
+	_parsetest{"x + 3", "3 + 1(x)"},
+	//4
+	_parsetest{", 7", "7"},
+}
+
+func TestTerms(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for i, tt := range ttTerms {
+		ls, err := Terms(strings.Split(tt.s, ", ")...)
+		if assert.Nil(err, "i=%d", i) {
+			assert.Equal(tt.expected, NewExpr(ls...).String(), "i=%d", i)
+		}
+	}
+
+	for _, ss := range [][]string{{"3x^"}, {"x", "2*"}, {"(x"}, {"x )"}} {
+		ls, err := Terms(ss...)
+		assert.Nil(ls, "%q", ss)
+		assert.IsType(&ParseError{}, err, "%q", ss)
+		assert.Panics(func() { MustTerms(ss...) }, "%q", ss)
+	}
+}
+
+func TestVars(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	vs, err := Vars("x^2", "", "y", "z^-0.5")
+	if assert.Nil(err) {
+		assert.Equal("x^2*y*z^-0.5", vs.String())
+	}
+	assert.Equal(vs, MustVars("x^2", "y", "z^-0.5"))
+
+	for _, ss := range [][]string{{"x^abc"}, {"x", "2x"}, {"x^"}, {"x*y"}} {
+		vs, err := Vars(ss...)
+		assert.Nil(vs, "%q", ss)
+		assert.IsType(&ParseError{}, err, "%q", ss)
+		assert.Panics(func() { MustVars(ss...) }, "%q", ss)
+	}
+}
+
 func ExampleEquation() {
-	left := Terms("3(x)", "-4(y)", "100")
-	right := Terms("55z", "6(x^3*y^4)")
+	left := MustTerms("3(x)", "-4(y)", "100")
+	right := MustTerms("55z", "6(x^3*y^4)")
 
 	eqn := Eqn(left)(LEQ)(right)
 
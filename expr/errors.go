@@ -1,3 +1,69 @@
 package expr
 
+import (
+	"bytes"
+	"fmt"
+)
+
 type ErrNoValuationForVar error
+
+// ParseError reports malformed input to the expression parser and to the
+// Terms and Vars builders.
+type ParseError struct {
+	Source   string   // the text being parsed
+	Offset   int      // byte offset of Token within Source
+	Line     int      // 1-based line of Token
+	Column   int      // 1-based column of Token, counted in runes
+	Token    string   // the offending token, empty at end of input
+	Expected []string // what would have been accepted, if known
+	Msg      string
+}
+
+func newParseError(src string, tok _Token, msg string, expected ...string) *ParseError {
+	o := &ParseError{
+		Source:   src,
+		Offset:   tok.offset,
+		Line:     1,
+		Column:   1,
+		Token:    tok.text,
+		Expected: expected,
+		Msg:      msg,
+	}
+
+	for _, r := range src[:tok.offset] {
+		if '\n' == r {
+			o.Line++
+			o.Column = 1
+		} else {
+			o.Column++
+		}
+	}
+	return o
+}
+
+func (this ParseError) Error() string {
+	buf := bytes.NewBufferString("")
+	buf.WriteString(fmt.Sprintf("expr: %d:%d: %s", this.Line, this.Column, this.Msg))
+	if 0 == len(this.Expected) {
+		return buf.String()
+	}
+
+	buf.WriteString(", expected ")
+	for i, s := range this.Expected {
+		if 0 < i && i == len(this.Expected)-1 {
+			buf.WriteString(" or ")
+		} else if 0 < i {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(s)
+	}
+	return buf.String()
+}
+
+type ErrNotPolynomial error
+type ErrDivisionByZero error
+type ErrZeroPolynomial error
+type ErrDomain error
+type ErrNotIntegrable error
+type ErrNotLinear error
+type ErrNotConverged error
@@ -22,6 +22,14 @@ type IValuation interface {
 	Get(varname string) (float64, bool)
 }
 
+// Valuation is a map based IValuation.
+type Valuation map[string]float64
+
+func (this Valuation) Get(varname string) (f float64, has bool) {
+	f, has = this[varname]
+	return
+}
+
 func (this Relation) Test(a, b float64) bool {
 	switch this {
 	case EQ:
@@ -58,3 +66,19 @@ func (this Relation) String() string {
 	}
 	return "<unkown relation>"
 }
+
+// Flip is the relation with its sides swapped, or after both sides are
+// negated, e.g. "<=" for ">=".
+func (this Relation) Flip() Relation {
+	switch this {
+	case LEQ:
+		return GEQ
+	case GEQ:
+		return LEQ
+	case Lesser:
+		return Greater
+	case Greater:
+		return Lesser
+	}
+	return this
+}
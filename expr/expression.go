@@ -22,27 +22,60 @@ type IExpression interface {
 	Clone() IExpression
 }
 
+// ValueOfExpr evaluates expr, terms with complex coefficients are an
+// ErrDomain; see ValueOfExprComplex.
 func ValueOfExpr(expr IExpression, m IValuation) (value float64, err error) {
 	expr.EachTerm(func(term ITerm) bool {
+		if isComplexCoeff(coeffOf(term)) {
+			err = ErrDomain(fmt.Errorf("%s has a complex coefficient", term))
+			return false
+		}
+		termValue := term.C()
 		for _, v := range term.Vars() {
-			c, has := m.Get(v.Name())
-			if !has {
-				err = ErrNoValuationForVar(fmt.Errorf("var=%s has no valuation", v.Name()))
+			if math0.IsApproxEqual(v.Power(), 0.0) {
+				continue
+			}
+			var c float64
+			if c, err = valueOfFactor(v, m); nil != err {
 				return false
 			}
-			if math0.IsApproxEqual(v.Power(), 0.0) {
-				value += term.C()
-			} else {
-				value += term.C() * math.Pow(c, v.Power())
+			if c, err = powOfFactor(v, c); nil != err {
+				return false
 			}
-
+			termValue *= c
 		}
+		value += termValue
 		return true
 	})
 
 	return
 }
 
+// powOfFactor raises c, the value of v without its power, to the power of
+// v: an ErrDivisionByZero for a negative power of 0, an ErrDomain for a
+// non-integral power of a negative value.
+func powOfFactor(v IVariable, c float64) (float64, error) {
+	if 0.0 == c && 0 > v.Power() {
+		return 0, ErrDivisionByZero(fmt.Errorf("%s is undefined at %s=0", v, v.Name()))
+	}
+	if 0 > c && !isIntegral(v.Power()) {
+		return 0, ErrDomain(fmt.Errorf("%s is undefined at %s=%v", v, v.Name(), c))
+	}
+	return math.Pow(c, v.Power()), nil
+}
+
+// valueOfFactor is the value of v without its power.
+func valueOfFactor(v IVariable, m IValuation) (float64, error) {
+	if fn, isFunc := v.(IFunction); isFunc {
+		return valueOfFunc(fn, m)
+	}
+	c, has := m.Get(v.Name())
+	if !has {
+		return 0, ErrNoValuationForVar(fmt.Errorf("var=%s has no valuation", v.Name()))
+	}
+	return c, nil
+}
+
 func SimplifyExpression(expr IExpression) (out TermList, bDidSomething bool) {
 
 	terms := expr.Terms()
@@ -57,21 +90,22 @@ func SimplifyExpression(expr IExpression) (out TermList, bDidSomething bool) {
 	}
 	bDidSomething = true
 
-	out = TermList{terms[0]}
-	outPrev := terms[0]
-	for i := 1; i < len(terms); i++ {
-		if math0.IsApproxEqual(terms[i].C(), 0.0) {
+	out = make(TermList, 0, len(terms))
+	for _, term := range terms {
+		c := coeffOf(term)
+		if isZeroCoeff(c) {
 			continue
 		}
 
-		if terms[i].Key() == outPrev.Key() {
-			outPrev.SetC(outPrev.C() + terms[i].C())
-			if math0.IsApproxEqual(outPrev.C(), 0.0) {
-				out = out[:len(out)-1]
+		n := len(out)
+		if 0 < n && term.Key() == out[n-1].Key() {
+			if c += coeffOf(out[n-1]); isZeroCoeff(c) {
+				out = out[:n-1]
+			} else {
+				out[n-1] = withCoeff(out[n-1], c)
 			}
 		} else {
-			out = append(out, terms[i])
-			outPrev = terms[i]
+			out = append(out, term)
 		}
 	}
 
@@ -137,7 +171,7 @@ func (this *_Expression) AddTerm(terms ...ITerm) {
 
 func (this *_Expression) SetTerms(terms ...ITerm) {
 	// this.key = nil // is set in AddTerm()
-	this.terms = this.terms[0:]
+	this.terms = nil
 	this.AddTerm(terms...)
 }
 
@@ -206,7 +240,12 @@ func (this _Expression) EachTerm(cb func(term ITerm) bool) {
 }
 
 func (this _Expression) Clone() IExpression {
+	terms := make(TermList, len(this.terms))
+	for i, term := range this.terms {
+		terms[i] = term.Clone()
+	}
+
 	o := NewExpr()
-	o.SetTerms(this.terms...)
+	o.SetTerms(terms...)
 	return o
 }
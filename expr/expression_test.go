@@ -243,9 +243,32 @@ func TestConstant(t *testing.T) {
 func TestWithVars(t *testing.T) {
 	assert := assertpkg.New(t)
 
-	eqn := Eqn(Terms("5(x*y^2)", "2(x*y)", "3(z)"))(EQ)(nil)
+	eqn := Eqn(MustTerms("5(x*y^2)", "2(x*y)", "3(z)"))(EQ)(nil)
 	assert.Equal("3(z)", eqn.Left().WithVars("z").String())
 	assert.Equal("5(x*y^2)", eqn.Left().WithVars("x*y^2").String())
 	assert.Equal("2(x*y)", eqn.Left().WithVars("x*y").String())
 
 }
+
+func TestValueOfExpr(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e, err := ParseExpr("x*y^2 + 3x + 2")
+	assert.Nil(err)
+	v, err := ValueOfExpr(e, Valuation{"x": 3, "y": 2})
+	assert.Nil(err)
+	assert.Equal(23.0, v)
+
+	_, err = ValueOfExpr(e, Valuation{"x": 3})
+	assert.NotNil(err)
+
+	e, err = ParseExpr("x^-2 + y^0.5")
+	assert.Nil(err)
+	v, err = ValueOfExpr(e, Valuation{"x": 2, "y": 4})
+	assert.Nil(err)
+	assert.Equal(2.25, v)
+	_, err = ValueOfExpr(e, Valuation{"x": 0, "y": 4})
+	assert.NotNil(err)
+	_, err = ValueOfExpr(e, Valuation{"x": 2, "y": -4})
+	assert.NotNil(err)
+}
@@ -0,0 +1,455 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// PolyFactor is an irreducible factor of a polynomial and the power it is
+// raised to. A constant factor also has its exact value in Content, which
+// Expr may only approximate.
+type PolyFactor struct {
+	Expr         IExpression
+	Multiplicity int
+	Content      *big.Rat // nil unless Expr is constant
+}
+
+// Factor factors a polynomial in one variable with rational coefficients
+// into irreducible factors over the integers, e.g. x^3 + x^2 - 5x + 3 into
+// (x - 1)^2 (x + 3). The content, with the sign of the leading coefficient,
+// is the first factor unless it is 1, so -2x^2 + 2 is -2 (x - 1) (x + 1);
+// then a power of the variable, if it divides e, then the others ordered by
+// degree. All but the content have integer coefficients and a positive
+// leading coefficient. A polynomial in several variables or with complex
+// coefficients is an ErrDomain, and so is a factor with a coefficient that
+// is not exact as a float64.
+func Factor(e IExpression) ([]PolyFactor, error) {
+	if err := checkPolynomial(e); nil != err {
+		return nil, err
+	}
+	if isZeroExpr(e) {
+		return nil, ErrZeroPolynomial(fmt.Errorf("the zero polynomial has no factors"))
+	}
+	vars := varNames(e)
+	if 1 < len(vars) {
+		return nil, ErrDomain(fmt.Errorf("factor: %s is not a polynomial in one variable", e))
+	}
+	if c, isConst := ConstantOf(e); isConst {
+		return []PolyFactor{PolyFactor{newExprOf(EqnBuilder_TermConstructor(c)), 1, RatOf(c)}}, nil
+	}
+	x := vars[0]
+
+	var coeffs []*big.Rat
+	var err error
+	e.EachTerm(func(term ITerm) bool {
+		if isComplexCoeff(coeffOf(term)) {
+			err = ErrDomain(fmt.Errorf("factor: %s has a complex coefficient", term))
+			return false
+		}
+		n := degreeOfTerm(term, x)
+		for len(coeffs) <= n {
+			coeffs = append(coeffs, new(big.Rat))
+		}
+		coeffs[n].Add(coeffs[n], RatOf(term.C()))
+		return true
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	var out []PolyFactor
+	content, f := primitiveOf(coeffs)
+	if 0 != content.Cmp(big.NewRat(1, 1)) {
+		c, _ := content.Float64()
+		out = append(out, PolyFactor{newExprOf(EqnBuilder_TermConstructor(c)), 1, content})
+	}
+
+	// x^k
+	k := 0
+	for 0 == f[k].Sign() {
+		k++
+	}
+	if 0 < k {
+		xk, _ := _IntPoly{big.NewInt(0), big.NewInt(1)}.expr(x) // x is exact
+		out = append(out, PolyFactor{xk, k, nil})
+		f = f[k:]
+	}
+
+	type _Factor struct {
+		g _IntPoly
+		m int
+	}
+	var factors []_Factor
+	for i, part := range squareFree(f) {
+		if 0 == part.degree() {
+			continue
+		}
+		irreducibles, err := factorSquareFree(part)
+		if nil != err {
+			return nil, err
+		}
+		for _, g := range irreducibles {
+			factors = append(factors, _Factor{g, i + 1})
+		}
+	}
+	sort.Slice(factors, func(i, j int) bool { return factors[i].g.less(factors[j].g) })
+	for _, factor := range factors {
+		g, err := factor.g.expr(x)
+		if nil != err {
+			return nil, err
+		}
+		out = append(out, PolyFactor{g, factor.m, nil})
+	}
+	return out, nil
+}
+
+// Factors writes fs as a product, e.g. "(x - 1)^2(x + 3)" with
+// ImplicitMul, FoldSigns and OrderByDegreeDesc. A factor of more than one
+// term is parenthesized unless it is alone, and so is a content that is
+// not an integer, e.g. "(1/6)(x + 1)(2x + 1)".
+func (this Printer) Factors(fs []PolyFactor) string {
+	if 0 == len(fs) {
+		return "1"
+	}
+
+	buf := bytes.NewBufferString("")
+	for i, f := range fs {
+		s := this.Expr(f.Expr)
+		_, isConst := ConstantOf(f.Expr)
+		if isConst && nil != f.Content {
+			s = f.Content.RatString()
+		}
+		switch {
+		case isConst && 0 == i && 1 < len(fs) && "-1" == s:
+			buf.WriteString("-")
+			continue
+		case isConst && 1 < len(fs) && nil != f.Content && !f.Content.IsInt():
+			s = "(" + s + ")"
+		case isConst:
+		case 1 < len(f.Expr.Terms()) && (1 < len(fs) || 1 < f.Multiplicity):
+			s = "(" + s + ")"
+		}
+		if 1 < f.Multiplicity {
+			power := fmt.Sprintf("%d", f.Multiplicity)
+			if sup, ok := superscript(power); ok && this.Unicode {
+				s += sup
+			} else {
+				s += "^" + power
+			}
+		}
+
+		if 0 < i && (!this.ImplicitMul || readsAsExponent(s)) && !strings.HasSuffix(buf.String(), "-") {
+			buf.WriteString("*")
+		}
+		buf.WriteString(s)
+	}
+	return buf.String()
+}
+
+// _IntPoly is a polynomial with integer coefficients, indexed by power and
+// without leading zeros.
+type _IntPoly []*big.Int
+
+// primitiveOf splits coeffs into the content, with the sign of the leading
+// coefficient, and a primitive polynomial with a positive leading one.
+func primitiveOf(coeffs []*big.Rat) (*big.Rat, _IntPoly) {
+	den := big.NewInt(1)
+	for _, c := range coeffs {
+		g := new(big.Int).GCD(nil, nil, den, c.Denom())
+		den.Mul(den, new(big.Int).Quo(c.Denom(), g))
+	}
+	f := make(_IntPoly, len(coeffs))
+	for i, c := range coeffs {
+		f[i] = new(big.Int).Mul(c.Num(), new(big.Int).Quo(den, c.Denom()))
+	}
+	f = f.trim()
+	num := f.content()
+	if 0 > f.lc().Sign() {
+		num.Neg(num)
+	}
+	return new(big.Rat).SetFrac(num, den), f.quoInt(num)
+}
+
+func (this _IntPoly) trim() _IntPoly {
+	for 0 < len(this) && 0 == this[len(this)-1].Sign() {
+		this = this[:len(this)-1]
+	}
+	return this
+}
+
+// less orders by degree, then by the coefficients from the leading one.
+func (this _IntPoly) less(q _IntPoly) bool {
+	if len(this) != len(q) {
+		return len(this) < len(q)
+	}
+	for i := len(this) - 1; 0 <= i; i-- {
+		if c := this[i].Cmp(q[i]); 0 != c {
+			return 0 > c
+		}
+	}
+	return false
+}
+
+func (this _IntPoly) degree() int {
+	return len(this) - 1
+}
+
+func (this _IntPoly) lc() *big.Int {
+	return this[len(this)-1]
+}
+
+// content is the gcd of the coefficients, 0 for the zero polynomial.
+func (this _IntPoly) content() *big.Int {
+	g := new(big.Int)
+	for _, c := range this {
+		g.GCD(nil, nil, g, new(big.Int).Abs(c))
+	}
+	return g
+}
+
+// primitive divides by the content and makes the leading coefficient
+// positive.
+func (this _IntPoly) primitive() _IntPoly {
+	if 0 == len(this) {
+		return this
+	}
+	g := this.content()
+	if 0 > this.lc().Sign() {
+		g.Neg(g)
+	}
+	return this.quoInt(g)
+}
+
+func (this _IntPoly) quoInt(c *big.Int) _IntPoly {
+	out := make(_IntPoly, len(this))
+	for i, a := range this {
+		out[i] = new(big.Int).Quo(a, c)
+	}
+	return out
+}
+
+func (this _IntPoly) mulInt(c *big.Int) _IntPoly {
+	out := make(_IntPoly, len(this))
+	for i, a := range this {
+		out[i] = new(big.Int).Mul(a, c)
+	}
+	return out.trim()
+}
+
+func (this _IntPoly) add(q _IntPoly) _IntPoly {
+	if len(this) < len(q) {
+		this, q = q, this
+	}
+	out := make(_IntPoly, len(this))
+	for i, a := range this {
+		out[i] = new(big.Int).Set(a)
+		if i < len(q) {
+			out[i].Add(out[i], q[i])
+		}
+	}
+	return out.trim()
+}
+
+func (this _IntPoly) sub(q _IntPoly) _IntPoly {
+	return this.add(q.mulInt(big.NewInt(-1)))
+}
+
+func (this _IntPoly) mul(q _IntPoly) _IntPoly {
+	if 0 == len(this) || 0 == len(q) {
+		return nil
+	}
+	out := make(_IntPoly, len(this)+len(q)-1)
+	for i := range out {
+		out[i] = new(big.Int)
+	}
+	for i, a := range this {
+		for j, b := range q {
+			out[i+j].Add(out[i+j], new(big.Int).Mul(a, b))
+		}
+	}
+	return out.trim()
+}
+
+func (this _IntPoly) derivative() _IntPoly {
+	if 1 >= len(this) {
+		return nil
+	}
+	out := make(_IntPoly, len(this)-1)
+	for i := range out {
+		out[i] = new(big.Int).Mul(this[i+1], big.NewInt(int64(i+1)))
+	}
+	return out.trim()
+}
+
+// divExact divides by q over the integers, false if q is not a factor.
+func (this _IntPoly) divExact(q _IntPoly) (_IntPoly, bool) {
+	if len(this) < len(q) {
+		return nil, 0 == len(this)
+	}
+	r := this.add(nil)
+	out := make(_IntPoly, len(this)-len(q)+1)
+	rem := new(big.Int)
+	for i := len(out) - 1; 0 <= i; i-- {
+		out[i] = new(big.Int)
+		if len(r) <= i+q.degree() {
+			continue
+		}
+		out[i].QuoRem(r[i+q.degree()], q.lc(), rem)
+		if 0 != rem.Sign() {
+			return nil, false
+		}
+		for j, b := range q {
+			r[i+j].Sub(r[i+j], new(big.Int).Mul(out[i], b))
+		}
+		r = r.trim()
+	}
+	return out.trim(), 0 == len(r)
+}
+
+// gcd is the primitive gcd with a positive leading coefficient, by
+// primitive remainder sequences.
+func (this _IntPoly) gcd(q _IntPoly) _IntPoly {
+	a, b := this.primitive(), q.primitive()
+	for 0 < len(b) {
+		a, b = b, a.pseudoRem(b).primitive()
+	}
+	return a
+}
+
+// pseudoRem is the remainder of lc(q)^k this by q, for some k.
+func (this _IntPoly) pseudoRem(q _IntPoly) _IntPoly {
+	r := this
+	for len(r) >= len(q) {
+		shift := make(_IntPoly, r.degree()-q.degree(), r.degree()-q.degree()+1)
+		for i := range shift {
+			shift[i] = new(big.Int)
+		}
+		shift = append(shift, r.lc())
+		r = r.mulInt(q.lc()).sub(shift.mul(q))
+	}
+	return r
+}
+
+// eval returns q^n this(p/q), n the degree, which is zero exactly when p/q
+// is a root.
+func (this _IntPoly) eval(p, q *big.Int) *big.Int {
+	sum := new(big.Int)
+	for i := len(this) - 1; 0 <= i; i-- {
+		sum.Mul(sum, p)
+		sum.Add(sum, new(big.Int).Mul(this[i], new(big.Int).Exp(q, big.NewInt(int64(len(this)-1-i)), nil)))
+	}
+	return sum
+}
+
+// expr converts to an expression in x, an ErrDomain if a coefficient would
+// be rounded.
+func (this _IntPoly) expr(x string) (IExpression, error) {
+	terms := make(TermList, 0, len(this))
+	for i, c := range this {
+		if 0 == c.Sign() {
+			continue
+		}
+		f, accuracy := new(big.Float).SetInt(c).Float64()
+		if big.Exact != accuracy {
+			return nil, ErrDomain(fmt.Errorf("factor: the coefficient %s is not exact as a float64", c))
+		}
+		if 0 == i {
+			terms = append(terms, EqnBuilder_TermConstructor(f))
+		} else {
+			terms = append(terms, EqnBuilder_TermConstructor(f, EqnBuilder_VarConstructor(x, float64(i))))
+		}
+	}
+	return newExprOf(terms...), nil
+}
+
+// squareFree is Yun's decomposition of a primitive polynomial, f is the
+// product of parts[i]^(i+1).
+func squareFree(f _IntPoly) (parts []_IntPoly) {
+	df := f.derivative()
+	b := f.gcd(df)
+	c, _ := f.divExact(b)
+	d, _ := df.divExact(b)
+	for 0 < c.degree() {
+		d = d.sub(c.derivative())
+		a := c.gcd(d)
+		parts = append(parts, a)
+		c, _ = c.divExact(a)
+		d, _ = d.divExact(a)
+	}
+	return
+}
+
+// factorSquareFree factors a primitive square-free polynomial, first
+// dividing out the linear factors of its rational roots. What remains is
+// irreducible if it is of degree 3 or below, otherwise it is left to
+// Zassenhaus.
+func factorSquareFree(f _IntPoly) ([]_IntPoly, error) {
+	if 1 >= f.degree() {
+		return []_IntPoly{f}, nil
+	}
+
+	linear, rest, bSearched := rationalRoots(f)
+	switch {
+	case 0 == rest.degree():
+		return linear, nil
+	case 1 == rest.degree() || (bSearched && 3 >= rest.degree()):
+		return append(linear, rest), nil
+	}
+	irreducibles, err := zassenhaus(rest)
+	if nil != err {
+		return nil, err
+	}
+	return append(linear, irreducibles...), nil
+}
+
+// rationalRoots divides out the factors qx - p of f, p/q a root with p
+// dividing the constant and q the leading coefficient. The candidates are
+// not searched if there are too many, e.g. for a large constant.
+func rationalRoots(f _IntPoly) (linear []_IntPoly, rest _IntPoly, bSearched bool) {
+	ps, okp := divisorsOf(f[0])
+	qs, okq := divisorsOf(f.lc())
+	if !okp || !okq || 4096 < len(ps)*len(qs) {
+		return nil, f, false
+	}
+
+	rest = f
+	g := new(big.Int)
+	for _, q := range qs {
+		for _, p := range ps {
+			for _, sp := range []*big.Int{p, new(big.Int).Neg(p)} {
+				if 1 >= rest.degree() {
+					break
+				}
+				if 1 != g.GCD(nil, nil, p, q).Int64() || 0 != rest.eval(sp, q).Sign() {
+					continue
+				}
+				factor := _IntPoly{new(big.Int).Neg(sp), q}
+				rest, _ = rest.divExact(factor)
+				linear = append(linear, factor)
+			}
+		}
+	}
+	return linear, rest, true
+}
+
+// divisorsOf returns the positive divisors of n by trial division, false if
+// |n| is too large to be factored that way.
+func divisorsOf(n *big.Int) ([]*big.Int, bool) {
+	if 40 < n.BitLen() {
+		return nil, false
+	}
+	m := new(big.Int).Abs(n).Int64()
+	var small, large []*big.Int
+	for d := int64(1); d*d <= m; d++ {
+		if 0 == m%d {
+			small = append(small, big.NewInt(d))
+			if d*d != m {
+				large = append([]*big.Int{big.NewInt(m / d)}, large...)
+			}
+		}
+	}
+	return append(small, large...), true
+}
@@ -0,0 +1,164 @@
+package expr
+
+import (
+	"math/rand"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+var ttFactor = []_parsetest{
+	//0
+	_parsetest{"x^3 + x^2 - 5x + 3", "(x - 1)^2(x + 3)"},
+	//1
+	_parsetest{"-2x^2 + 2", "-2(x - 1)(x + 1)"},
+	//2
+	_parsetest{"x^4 - 1", "(x - 1)(x + 1)(x^2 + 1)"},
+	//3
+	_parsetest{"x^5 - x^3", "x^3(x - 1)(x + 1)"},
+	//4
+	_parsetest{"0.5y^2 - 0.5", "(1/2)(y - 1)(y + 1)"},
+	//5
+	_parsetest{"6x^2 + x - 2", "(2x - 1)(3x + 2)"},
+	//6
+	_parsetest{"x^4 + 4", "(x^2 - 2x + 2)(x^2 + 2x + 2)"},
+	//7
+	_parsetest{"x^4 + 1", "x^4 + 1"},
+	//8
+	_parsetest{"-x", "-x"},
+	//9
+	_parsetest{"3", "3"},
+	//10
+	_parsetest{"x^6 - 2x^3 + 1", "(x - 1)^2(x^2 + x + 1)^2"},
+	//11
+	_parsetest{"x^2/3 + x/2 + 1/6", "(1/6)(x + 1)(2x + 1)"},
+	//12
+	_parsetest{"1000003x^2 - 1000003", "1000003(x - 1)(x + 1)"},
+	//13
+	_parsetest{"-x^2/2 + 1/2", "(-1/2)(x - 1)(x + 1)"},
+	//14
+	_parsetest{"0.25", "1/4"},
+}
+
+func TestFactor(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	p := Printer{FoldSigns: true, ImplicitMul: true, Order: OrderByDegreeDesc}
+	for i, tt := range ttFactor {
+		e := mustParse(tt.s)
+		fs, err := Factor(e)
+		if assert.Nil(err, "i=%d", i) {
+			assert.Equal(tt.expected, p.Factors(fs), "i=%d", i)
+			assert.True(EqualExpr(e, productOf(fs), 1e-9), "i=%d", i)
+		}
+	}
+
+	// x^n - 1 is the product of the cyclotomic polynomials of the divisors
+	// of n
+	for n, count := range map[int]int{12: 6, 15: 4, 16: 5} {
+		fs, err := Factor(Sub(mustPow(mustParse("x"), n), mustParse("1")))
+		if assert.Nil(err, "n=%d", n) {
+			assert.Len(fs, count, "n=%d", n)
+		}
+	}
+
+	// irreducible, but it splits mod every prime
+	fs, err := Factor(mustParse("x^8 - 40x^4*x^2 + 352x^4 - 960x^2 + 576"))
+	if assert.Nil(err) {
+		assert.Len(fs, 1)
+	}
+
+	for _, s := range []string{"0", "x*y + 1", "sin(x) + 1", "x^-1 + 1", "x^0.5"} {
+		_, err := Factor(mustParse(s))
+		assert.NotNil(err, s)
+	}
+	_, err = Factor(NewExpr(NewComplexTerm(1i, NewVar("x")), NewTerm(1)))
+	assert.NotNil(err)
+
+	// the content is exact, not its float64
+	fs, err = Factor(mustParse("x^2/3 + x/2 + 1/6"))
+	if assert.Nil(err) {
+		assert.Equal("1/6", fs[0].Content.RatString())
+		assert.Nil(fs[1].Content)
+	}
+
+	// clearing the denominators gives coefficients above 2^53
+	_, err = Factor(mustParse("x^4/16381 + x^3/16363 + x^2/16369 + x/16361 + 1/16349"))
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "not exact")
+	}
+}
+
+func TestFactor_Random(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	rnd := rand.New(rand.NewSource(7))
+	x := mustParse("x")
+	for n := 0; n < 40; n++ {
+		// products of up to 4 random factors, some repeated, which
+		// without rational roots are left to Zassenhaus
+		e := NewExpr(NewTerm(1))
+		nfactors := 0
+		for i := 1 + rnd.Intn(4); 0 < i; i-- {
+			g := NewExpr(NewTerm(float64(1 + rnd.Intn(3))))
+			for d := 1 + rnd.Intn(3); 0 < d; d-- {
+				g = Mul(g, x)
+				g = Add(g, NewExpr(NewTerm(float64(rnd.Intn(201)-100))))
+			}
+			e = Mul(e, mustPow(g, 1+rnd.Intn(2)))
+			nfactors++
+		}
+
+		fs, err := Factor(e)
+		if !assert.Nil(err, "%s", e) {
+			continue
+		}
+		assert.True(EqualExpr(e, productOf(fs), 1e-6*maxCoeff(e)), "%s\n%s", e, Printer{}.Factors(fs))
+		for _, f := range fs {
+			f.Expr.EachTerm(func(term ITerm) bool {
+				assert.True(isIntegral(term.C()), "%s", f.Expr)
+				return true
+			})
+		}
+	}
+}
+
+func productOf(fs []PolyFactor) IExpression {
+	e := NewExpr(NewTerm(1))
+	for _, f := range fs {
+		e = Mul(e, mustPow(f.Expr, f.Multiplicity))
+	}
+	return e
+}
+
+func maxCoeff(e IExpression) (c float64) {
+	e.EachTerm(func(term ITerm) bool {
+		if c < term.C() || c < -term.C() {
+			c = term.C()
+			if 0 > c {
+				c = -c
+			}
+		}
+		return true
+	})
+	return
+}
+
+func TestPrinter_Factors(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	fs, err := Factor(mustParse("-x^3 - 2x^2 - x"))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Equal("-x(x + 1)^2", Printer{FoldSigns: true, ImplicitMul: true, Order: OrderByDegreeDesc}.Factors(fs))
+	assert.Equal("-x(x + 1)²", Printer{FoldSigns: true, ImplicitMul: true, Unicode: true, Order: OrderByDegreeDesc}.Factors(fs))
+	assert.Equal("-x*(1 + x)^2", Printer{}.Factors(fs))
+	assert.Equal("1", Printer{}.Factors(nil))
+
+	// "3e1" would read as 30
+	fs, err = Factor(mustParse("3*e1^2 + 3*e1"))
+	if assert.Nil(err) {
+		assert.Equal("3*e1(e1 + 1)", Printer{FoldSigns: true, ImplicitMul: true, Order: OrderByDegreeDesc}.Factors(fs))
+	}
+}
@@ -0,0 +1,285 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/noypi/math0"
+)
+
+type FuncKind int
+
+const (
+	FuncSin FuncKind = iota
+	FuncCos
+	FuncTan
+	FuncExp
+	FuncLog // natural logarithm
+	FuncSqrt
+	FuncAbs
+	FuncPow // pow(base, exponent) with a symbolic exponent
+)
+
+var g_funcNames = map[string]FuncKind{
+	"sin":  FuncSin,
+	"cos":  FuncCos,
+	"tan":  FuncTan,
+	"exp":  FuncExp,
+	"log":  FuncLog,
+	"sqrt": FuncSqrt,
+	"abs":  FuncAbs,
+	"pow":  FuncPow,
+}
+
+// IFunction is a factor of a term applying a function to expressions, e.g.
+// sin(x*y)^2 in 3*sin(x*y)^2. Its Name() is the canonical text of the
+// application, so equal applications combine like equal variables.
+type IFunction interface {
+	IVariable
+	Kind() FuncKind
+	Args() []IExpression
+}
+
+type _Function struct {
+	kind  FuncKind
+	args  []IExpression
+	power float64
+	name  string
+}
+
+// NewFunc returns kind applied to args, FuncPow takes two args and the
+// others one.
+func NewFunc(kind FuncKind, args ...IExpression) IFunction {
+	return NewFuncN(kind, 1.0, args...)
+}
+
+// NewFuncN returns kind applied to args, raised to power.
+func NewFuncN(kind FuncKind, power float64, args ...IExpression) IFunction {
+	o := &_Function{kind: kind, power: power}
+	o.args = make([]IExpression, len(args))
+	for i, arg := range args {
+		o.args[i] = arg.Clone()
+	}
+
+	buf := bytes.NewBufferString(kind.String())
+	buf.WriteString("(")
+	for i, arg := range o.args {
+		if 0 < i {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(exprText(arg))
+	}
+	buf.WriteString(")")
+	o.name = buf.String()
+	return o
+}
+
+func (this FuncKind) String() string {
+	switch this {
+	case FuncSin:
+		return "sin"
+	case FuncCos:
+		return "cos"
+	case FuncTan:
+		return "tan"
+	case FuncExp:
+		return "exp"
+	case FuncLog:
+		return "log"
+	case FuncSqrt:
+		return "sqrt"
+	case FuncAbs:
+		return "abs"
+	case FuncPow:
+		return "pow"
+	}
+	return "<unknown function>"
+}
+
+// Arity is the number of arguments of the function.
+func (this FuncKind) Arity() int {
+	if FuncPow == this {
+		return 2
+	}
+	return 1
+}
+
+func (this _Function) Name() string {
+	return this.name
+}
+
+func (this _Function) Power() float64 {
+	return this.power
+}
+
+func (this _Function) Kind() FuncKind {
+	return this.kind
+}
+
+func (this _Function) Args() []IExpression {
+	return this.args
+}
+
+func (this *_Function) AddPower(n float64) IVariable {
+	o := *this
+	o.power += n
+	return &o
+}
+
+func (this _Function) String() string {
+	if math0.IsApproxEqual(this.power, 1.0) {
+		return this.name
+	}
+	return fmt.Sprintf("%s^%s", this.name, ToTrimZero(this.power))
+}
+
+// Apply evaluates the function at already evaluated arguments.
+func (this FuncKind) Apply(args ...float64) (f float64, err error) {
+	u := args[0]
+	switch this {
+	case FuncSin:
+		f = math.Sin(u)
+	case FuncCos:
+		f = math.Cos(u)
+	case FuncTan:
+		f = math.Tan(u)
+	case FuncExp:
+		f = math.Exp(u)
+	case FuncLog:
+		if 0 >= u {
+			err = ErrDomain(fmt.Errorf("log(%v) is undefined", u))
+		}
+		f = math.Log(u)
+	case FuncSqrt:
+		if 0 > u {
+			err = ErrDomain(fmt.Errorf("sqrt(%v) is undefined", u))
+		}
+		f = math.Sqrt(u)
+	case FuncAbs:
+		f = math.Abs(u)
+	case FuncPow:
+		if 0 == u && 0 > args[1] {
+			err = ErrDivisionByZero(fmt.Errorf("pow(0, %v) is undefined", args[1]))
+		} else if 0 > u && !isIntegral(args[1]) {
+			err = ErrDomain(fmt.Errorf("pow(%v, %v) is undefined", u, args[1]))
+		}
+		f = math.Pow(u, args[1])
+	default:
+		err = fmt.Errorf("unknown function %d", int(this))
+	}
+	return
+}
+
+func valueOfFunc(fn IFunction, m IValuation) (float64, error) {
+	args := make([]float64, len(fn.Args()))
+	for i, arg := range fn.Args() {
+		var err error
+		if args[i], err = ValueOfExpr(arg, m); nil != err {
+			return 0, err
+		}
+	}
+	return fn.Kind().Apply(args...)
+}
+
+// FuncExpr is the expression 1*kind(args...)^power.
+func FuncExpr(kind FuncKind, power float64, args ...IExpression) IExpression {
+	return newExprOf(EqnBuilder_TermConstructor(1, NewFuncN(kind, power, args...)))
+}
+
+// derivativeOfFunc returns d(fn)/d(varname) by the chain rule.
+func derivativeOfFunc(fn IFunction, varname string) IExpression {
+	args := fn.Args()
+	u := args[0]
+	du := Derivative(u, varname)
+
+	var d IExpression
+	switch fn.Kind() {
+	case FuncSin:
+		d = Mul(FuncExpr(FuncCos, 1, u), du)
+	case FuncCos:
+		d = Neg(Mul(FuncExpr(FuncSin, 1, u), du))
+	case FuncTan:
+		d = Mul(Add(newExprOf(NewTerm(1)), FuncExpr(FuncTan, 2, u)), du)
+	case FuncExp:
+		d = Mul(FuncExpr(FuncExp, 1, u), du)
+	case FuncLog:
+		d = Mul(reciprocal(u), du)
+	case FuncSqrt:
+		d = scaleExpr(Mul(FuncExpr(FuncSqrt, -1, u), du), 0.5)
+	case FuncAbs:
+		d = Mul(Mul(FuncExpr(FuncAbs, 1, u), reciprocal(u)), du)
+	case FuncPow:
+		// d(b^e) = b^e * (e' log(b) + e b'/b)
+		b, e := args[0], args[1]
+		de := Derivative(e, varname)
+		inner := Add(Mul(de, FuncExpr(FuncLog, 1, b)), Mul(Mul(e, du), reciprocal(b)))
+		d = Mul(FuncExpr(FuncPow, 1, b, e), inner)
+	}
+
+	if math0.IsApproxEqual(fn.Power(), 1.0) || isZeroExpr(d) {
+		return d
+	}
+	outer := newExprOf(NewTerm(fn.Power(), fn.AddPower(-1)))
+	return Mul(outer, d)
+}
+
+// reciprocal returns 1/e, as pow(e, -1) if e has several terms.
+func reciprocal(e IExpression) IExpression {
+	if inverse, err := Pow(e, -1); nil == err {
+		return inverse
+	}
+	return FuncExpr(FuncPow, 1, e, newExprOf(NewTerm(-1)))
+}
+
+// dependsOn reports whether v is, or is a function of, varname.
+func dependsOn(v IVariable, varname string) bool {
+	fn, isFunc := v.(IFunction)
+	if !isFunc {
+		return v.Name() == varname
+	}
+	for _, arg := range fn.Args() {
+		for _, name := range varNames(arg) {
+			if name == varname {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exprText is the canonical text of e used in function names, e.g.
+// "-1 + 2*x*y".
+func exprText(e IExpression) string {
+	if 0 == len(e.Terms()) {
+		return "0"
+	}
+
+	buf := bytes.NewBufferString("")
+	e.EachTerm(func(term ITerm) bool {
+		if 0 < buf.Len() {
+			buf.WriteString(" + ")
+		}
+		cc := coeffOf(term)
+		c := strconv.FormatFloat(real(cc), 'g', -1, 64)
+		if isComplexCoeff(cc) {
+			c = strconv.FormatComplex(cc, 'g', -1, 128)
+		}
+		switch {
+		case 0 == len(term.Vars()):
+			buf.WriteString(c)
+		case 1 == cc:
+			buf.WriteString(term.Vars().String())
+		case -1 == cc:
+			buf.WriteString("-")
+			buf.WriteString(term.Vars().String())
+		default:
+			buf.WriteString(c)
+			buf.WriteString("*")
+			buf.WriteString(term.Vars().String())
+		}
+		return true
+	})
+	return buf.String()
+}
@@ -0,0 +1,65 @@
+package expr
+
+import (
+	"math"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+var ttFunctions = []_parsetest{
+	//0
+	_parsetest{"3*sin(x*y) + exp(z)", "1(exp(z)) + 3(sin(x*y))"},
+	//1
+	_parsetest{"sin(x)*sin(x) + 2sin(x)^2", "3(sin(x)^2)"},
+	//2
+	_parsetest{"log(x^2 + 1) - log(1 + x*x)", "0"},
+	//3
+	_parsetest{"x^y + pow(x, y)", "2(pow(x, y))"},
+	//4
+	_parsetest{"sqrt(4) + abs(-2)", "4"},
+}
+
+func TestFunction(t *testing.T) {
+	assert := assertpkg.New(t)
+	for i, tt := range ttFunctions {
+		e, err := ParseExpr(tt.s)
+		if assert.Nil(err, "i=%d", i) {
+			assert.Equal(tt.expected, e.String(), "i=%d", i)
+			assert.Equal(tt.expected, e.Clone().String(), "i=%d", i)
+		}
+	}
+
+	for _, s := range []string{"log(-1)", "pow(x)", "sin(x, y)", "sin(x"} {
+		_, err := ParseExpr(s)
+		assert.NotNil(err, "s=%q", s)
+	}
+
+	v, err := ValueOfExpr(mustParse("3*sin(x*y) + exp(z)"), Valuation{"x": 0.5, "y": 1, "z": 0})
+	assert.Nil(err)
+	assert.InDelta(3*math.Sin(0.5)+1, v, 1e-12)
+
+	_, err = ValueOfExpr(mustParse("log(x)"), Valuation{"x": -1})
+	assert.NotNil(err)
+}
+
+func TestFunction_Derivative(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	assert.Equal("3(cos(x*y)*y)", Derivative(mustParse("3*sin(x*y) + exp(z)"), "x").String())
+	assert.Equal("0", Derivative(mustParse("sin(x)^2 + cos(x)^2"), "x").String())
+	assert.Equal("1 + 1(tan(x)^2)", Derivative(mustParse("tan(x)"), "x").String())
+	assert.Equal("1(pow(x, y)*x^-1*y)", Derivative(mustParse("x^y"), "x").String())
+
+	// compare with a central difference
+	for _, s := range []string{"sin(x)^2*y", "log(x^2 + y)", "sqrt(x*y + 1)", "pow(x, x*y)", "abs(x - 2)*exp(-x)"} {
+		e := mustParse(s)
+		d := Derivative(e, "x")
+		const h = 1e-6
+		v, err := ValueOfExpr(d, Valuation{"x": 0.7, "y": 2})
+		assert.Nil(err, s)
+		v1, _ := ValueOfExpr(e, Valuation{"x": 0.7 + h, "y": 2})
+		v0, _ := ValueOfExpr(e, Valuation{"x": 0.7 - h, "y": 2})
+		assert.InDelta((v1-v0)/(2*h), v, 1e-6, "%s: %s", s, d)
+	}
+}
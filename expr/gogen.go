@@ -0,0 +1,275 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	gofmt "go/format"
+	"go/token"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var g_goFuncs = map[FuncKind]string{
+	FuncSin:  "math.Sin",
+	FuncCos:  "math.Cos",
+	FuncTan:  "math.Tan",
+	FuncExp:  "math.Exp",
+	FuncLog:  "math.Log",
+	FuncSqrt: "math.Sqrt",
+	FuncAbs:  "math.Abs",
+	FuncPow:  "math.Pow",
+}
+
+// GenerateGo writes e as a Go function taking the variables in vars, e.g.
+// 3x^2 - 2x + 1 as
+//
+//	func f(x float64) float64 {
+//		return (3*x-2)*x + 1
+//	}
+//
+// Integral powers of vars are nested in Horner form, in the order of vars,
+// and multiplied out; functions are evaluated once each. The function
+// needs package math only if e has functions or fractional powers.
+func GenerateGo(w io.Writer, funcName string, e IExpression, vars []string) error {
+	g := _GoGen{
+		index: make(map[string]int, len(vars)),
+		funcs: map[string]string{},
+	}
+	if !token.IsIdentifier(funcName) {
+		return fmt.Errorf("expr: %q is not a Go identifier", funcName)
+	}
+	for i, name := range vars {
+		if _, has := g.index[name]; has || !token.IsIdentifier(name) || name == funcName || "math" == name {
+			return fmt.Errorf("expr: %q cannot be a parameter", name)
+		}
+		g.index[name] = i
+	}
+	g.vars = vars
+
+	body, err := g.expr(e)
+	if nil != err {
+		return err
+	}
+
+	buf := bytes.NewBufferString("")
+	fmt.Fprintf(buf, "// %s evaluates %s.\n", funcName, Printer{FoldSigns: true}.Expr(e))
+	fmt.Fprintf(buf, "func %s(", funcName)
+	if 0 < len(vars) {
+		fmt.Fprintf(buf, "%s float64", strings.Join(vars, ", "))
+	}
+	buf.WriteString(") float64 {\n")
+	for _, line := range g.locals {
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(buf, "return %s\n}\n", body.text)
+
+	src, err := gofmt.Source(buf.Bytes())
+	if nil != err {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type _GoGen struct {
+	vars   []string
+	index  map[string]int
+	funcs  map[string]string // local of each function, by Name()
+	locals []string
+}
+
+// _GoTerm is a term with the integral powers of vars split off, the other
+// factors already written as Go.
+type _GoTerm struct {
+	c       float64
+	powers  []int
+	factors []string
+}
+
+// _GoCode is Go source of a float64 expression.
+type _GoCode struct {
+	text  string
+	isSum bool // whether it needs parentheses as a factor
+}
+
+func (this *_GoGen) expr(e IExpression) (code _GoCode, err error) {
+	terms := make([]_GoTerm, 0, len(e.Terms()))
+	e.EachTerm(func(term ITerm) bool {
+		if isComplexCoeff(coeffOf(term)) {
+			err = ErrDomain(fmt.Errorf("%s has a complex coefficient", term))
+			return false
+		}
+		t := _GoTerm{c: term.C(), powers: make([]int, len(this.vars))}
+		for _, v := range term.Vars() {
+			var f string
+			if f, err = this.factor(v, &t); nil != err {
+				return false
+			}
+			if "" != f {
+				t.factors = append(t.factors, f)
+			}
+		}
+		terms = append(terms, t)
+		return true
+	})
+	if nil != err {
+		return
+	}
+	return this.horner(terms, 0), nil
+}
+
+// factor adds a positive integral power of a var to t.powers, or returns
+// the factor as Go.
+func (this *_GoGen) factor(v IVariable, t *_GoTerm) (string, error) {
+	p := v.Power()
+	name := v.Name()
+	if fn, isFunc := v.(IFunction); isFunc {
+		var err error
+		if name, err = this.function(fn); nil != err {
+			return "", err
+		}
+	} else if i, has := this.index[name]; !has {
+		return "", ErrNoValuationForVar(fmt.Errorf("var=%s is not a parameter", name))
+	} else if isIntegral(p) && 0 < p {
+		t.powers[i] += int(math.Round(p))
+		return "", nil
+	}
+
+	switch {
+	case !isIntegral(p):
+		return "math.Pow(" + name + ", " + goNumber(p) + ")", nil
+	case 0 > p:
+		return "1/" + goPow(name, -int(math.Round(p)), true), nil
+	}
+	return goPow(name, int(math.Round(p)), false), nil
+}
+
+// function returns the local holding fn without its power, declaring it
+// after the locals of its arguments.
+func (this *_GoGen) function(fn IFunction) (string, error) {
+	key := NewFunc(fn.Kind(), fn.Args()...).Name()
+	if local, has := this.funcs[key]; has {
+		return local, nil
+	}
+
+	args := make([]string, 0, len(fn.Args()))
+	for _, arg := range fn.Args() {
+		code, err := this.expr(arg)
+		if nil != err {
+			return "", err
+		}
+		args = append(args, code.text)
+	}
+
+	local := ""
+	for i := len(this.funcs); "" == local; i++ {
+		local = "t" + strconv.Itoa(i)
+		if _, has := this.index[local]; has {
+			local = ""
+		}
+	}
+	this.funcs[key] = local
+	this.locals = append(this.locals, local+" := "+g_goFuncs[fn.Kind()]+"("+strings.Join(args, ", ")+")")
+	return local, nil
+}
+
+// horner writes terms nested by the powers of vars[i:], e.g. x^3*a + x*b + c
+// as (x*x*a + b)*x + c.
+func (this *_GoGen) horner(terms []_GoTerm, i int) _GoCode {
+	if 0 == len(terms) {
+		return _GoCode{text: "0"}
+	}
+	if len(this.vars) == i {
+		return goSum(terms)
+	}
+
+	groups := map[int][]_GoTerm{}
+	for _, t := range terms {
+		groups[t.powers[i]] = append(groups[t.powers[i]], t)
+	}
+	degrees := make([]int, 0, len(groups))
+	for d := range groups {
+		degrees = append(degrees, d)
+	}
+	sort.Ints(degrees)
+
+	x := this.vars[i]
+	k := len(degrees) - 1
+	acc := this.horner(groups[degrees[k]], i+1)
+	for k--; 0 <= k; k-- {
+		acc = goMul(acc, goPow(x, degrees[k+1]-degrees[k], false))
+		acc = goAdd(acc, this.horner(groups[degrees[k]], i+1))
+	}
+	if d := degrees[0]; 0 < d {
+		acc = goMul(acc, goPow(x, d, false))
+	}
+	return acc
+}
+
+func goSum(terms []_GoTerm) _GoCode {
+	code := _GoCode{}
+	for _, t := range terms {
+		product := strings.Join(t.factors, "*")
+		s := goNumber(t.c)
+		switch {
+		case 0 == len(product):
+		case 1 == t.c:
+			s = product
+		case -1 == t.c:
+			s = "-" + product
+		default:
+			s += "*" + product
+		}
+		code = goAdd(code, _GoCode{text: s})
+	}
+	return code
+}
+
+func goAdd(a, b _GoCode) _GoCode {
+	switch {
+	case "" == a.text:
+		return b
+	case strings.HasPrefix(b.text, "-"):
+		return _GoCode{text: a.text + " - " + b.text[1:], isSum: true}
+	}
+	return _GoCode{text: a.text + " + " + b.text, isSum: true}
+}
+
+func goMul(a _GoCode, factor string) _GoCode {
+	switch {
+	case "1" == a.text:
+		return _GoCode{text: factor}
+	case "-1" == a.text:
+		return _GoCode{text: "-" + factor}
+	case a.isSum:
+		return _GoCode{text: "(" + a.text + ")*" + factor}
+	}
+	return _GoCode{text: a.text + "*" + factor}
+}
+
+// goPow multiplies out name^n for n > 0, grouped for a divisor.
+func goPow(name string, n int, isDivisor bool) string {
+	s := strings.Repeat(name+"*", n)
+	s = s[:len(s)-1]
+	if isDivisor && 1 < n {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// goNumber writes f as a Go float constant.
+func goNumber(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "math.Inf(1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	case math.IsNaN(f):
+		return "math.NaN()"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,159 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+var ttGenerateGo = []_parsetest{
+	//0
+	_parsetest{"3x^2 - 2x + 1", "return (3*x-2)*x + 1"},
+	//1
+	_parsetest{"x^5 + 2x", "return (x*x*x*x + 2) * x"},
+	//2
+	_parsetest{"x^2*y + x*y^3 - 4", "return (y*x+y*y*y)*x - 4"},
+	//3
+	_parsetest{"y^-2 + x^-1", "return 1/x + 1/(y*y)"},
+	//4
+	_parsetest{"sin(x)^2 + cos(x)^2 + sin(x)", "t0 := math.Cos(x)\n\tt1 := math.Sin(x)\n\treturn t0*t0 + t1 + t1*t1"},
+	//5
+	_parsetest{"0", "return 0"},
+}
+
+func TestGenerateGo(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for i, tt := range ttGenerateGo {
+		buf := bytes.NewBufferString("")
+		err := GenerateGo(buf, "f", mustParse(tt.s), []string{"x", "y"})
+		assert.Nil(err, "i=%d", i)
+		assert.Contains(buf.String(), "func f(x, y float64) float64 {\n\t"+tt.expected+"\n}\n", "i=%d", i)
+	}
+
+	for _, s := range []string{
+		"3x^2*y - 2x*y^2 + y^-3 - 7",
+		"pow(x+1, 0.5)*y + exp(abs(x - y))^3 - log(sqrt(x^2 + 1))^-1",
+		"2.5e-7x^10 - 1e12y^0.5 + tan(cos(x)*sin(y))",
+		"x*y*z^4 - z^-2*x + 6",
+	} {
+		e := mustParse(s)
+		buf := bytes.NewBufferString("")
+		err := GenerateGo(buf, "f", e, []string{"x", "y", "z"})
+		if !assert.Nil(err, s) {
+			continue
+		}
+		f, err := parseGoFunc(buf.String())
+		if !assert.Nil(err, "%s\n%s", s, buf.String()) {
+			continue
+		}
+
+		for _, m := range []Valuation{
+			Valuation{"x": 0.5, "y": 2, "z": -1.5},
+			Valuation{"x": 3, "y": 0.25, "z": 7},
+		} {
+			expected, err := ValueOfExpr(e, m)
+			assert.Nil(err)
+			assert.InDelta(expected, f(m), 1e-9*math.Max(1, math.Abs(expected)), "%s at %v\n%s", s, m, buf.String())
+		}
+	}
+
+	for _, vars := range [][]string{{"x"}, {"x", "x", "y"}, {"x", "y", "f"}, {"x", "math", "y"}, {"x", "1y"}} {
+		err := GenerateGo(bytes.NewBufferString(""), "f", mustParse("x + y"), vars)
+		assert.NotNil(err, "%v", vars)
+	}
+	assert.NotNil(GenerateGo(bytes.NewBufferString(""), "func", mustParse("x"), []string{"x"}))
+	assert.NotNil(GenerateGo(bytes.NewBufferString(""), "f", NewExpr(NewComplexTerm(1i)), nil))
+}
+
+func TestGenerateGo_Locals(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// t0 is taken by a parameter
+	buf := bytes.NewBufferString("")
+	assert.Nil(GenerateGo(buf, "f", mustParse("sin(t0)"), []string{"t0"}))
+	assert.Contains(buf.String(), "t1 := math.Sin(t0)\n\treturn t1\n")
+}
+
+// parseGoFunc evaluates the generated function with go/ast, so that the
+// tests need not build it.
+func parseGoFunc(src string) (func(m Valuation) float64, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+src, 0)
+	if nil != err {
+		return nil, err
+	}
+	body := file.Decls[0].(*ast.FuncDecl).Body.List
+
+	return func(m Valuation) float64 {
+		locals := Valuation{}
+		for k, v := range m {
+			locals[k] = v
+		}
+		for _, stmt := range body {
+			switch s := stmt.(type) {
+			case *ast.AssignStmt:
+				locals[s.Lhs[0].(*ast.Ident).Name] = evalGoExpr(s.Rhs[0], locals)
+			case *ast.ReturnStmt:
+				return evalGoExpr(s.Results[0], locals)
+			}
+		}
+		panic("no return")
+	}, nil
+}
+
+func evalGoExpr(e ast.Expr, m Valuation) float64 {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		f, _ := strconv.ParseFloat(e.Value, 64)
+		return f
+	case *ast.Ident:
+		return m[e.Name]
+	case *ast.ParenExpr:
+		return evalGoExpr(e.X, m)
+	case *ast.UnaryExpr:
+		return -evalGoExpr(e.X, m)
+	case *ast.BinaryExpr:
+		a, b := evalGoExpr(e.X, m), evalGoExpr(e.Y, m)
+		switch e.Op {
+		case token.ADD:
+			return a + b
+		case token.SUB:
+			return a - b
+		case token.MUL:
+			return a * b
+		case token.QUO:
+			return a / b
+		}
+	case *ast.CallExpr:
+		args := make([]float64, 0, len(e.Args))
+		for _, arg := range e.Args {
+			args = append(args, evalGoExpr(arg, m))
+		}
+		switch e.Fun.(*ast.SelectorExpr).Sel.Name {
+		case "Sin":
+			return math.Sin(args[0])
+		case "Cos":
+			return math.Cos(args[0])
+		case "Tan":
+			return math.Tan(args[0])
+		case "Exp":
+			return math.Exp(args[0])
+		case "Log":
+			return math.Log(args[0])
+		case "Sqrt":
+			return math.Sqrt(args[0])
+		case "Abs":
+			return math.Abs(args[0])
+		case "Pow":
+			return math.Pow(args[0], args[1])
+		}
+	}
+	panic(fmt.Sprintf("unexpected %T", e))
+}
@@ -0,0 +1,114 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/noypi/math0"
+)
+
+// Integrate returns an antiderivative of e with respect to varname, e.g.
+// c*x^n*y^m integrates to c/(n+1)*x^(n+1)*y^m and c*x^-1 to
+// c*log(abs(x)). The constant of integration is omitted. A term with a
+// function of varname is an ErrNotIntegrable.
+func Integrate(e IExpression, varname string) (IExpression, error) {
+	var terms TermList
+	var err error
+	e.EachTerm(func(term ITerm) bool {
+		var iterm ITerm
+		if iterm, err = integralOfTerm(term, varname); nil != err {
+			return false
+		}
+		terms = append(terms, iterm)
+		return true
+	})
+	if nil != err {
+		return nil, err
+	}
+	return newExprOf(terms...), nil
+}
+
+// DefiniteIntegral integrates e over varname from a to b. Other variables
+// are taken from m, which may be nil if there are none. A term with a power
+// of varname of -1 or below diverges at 0, which is an ErrDivisionByZero if
+// 0 is in [a, b].
+func DefiniteIntegral(e IExpression, varname string, a, b float64, m IValuation) (float64, error) {
+	if math.Min(a, b) <= 0 && 0 <= math.Max(a, b) {
+		var err error
+		e.EachTerm(func(term ITerm) bool {
+			if v := term.Var(varname); nil != v && v.Power() <= -1 {
+				err = ErrDivisionByZero(fmt.Errorf("the integral of %s from %v to %v diverges at %s=0", term, a, b, varname))
+			}
+			return nil == err
+		})
+		if nil != err {
+			return 0, err
+		}
+	}
+
+	antiderivative, err := Integrate(e, varname)
+	if nil != err {
+		return 0, err
+	}
+
+	fb, err := ValueOfExpr(antiderivative, _BoundValuation{varname, b, m})
+	if nil != err {
+		return 0, err
+	}
+	fa, err := ValueOfExpr(antiderivative, _BoundValuation{varname, a, m})
+	if nil != err {
+		return 0, err
+	}
+	return fb - fa, nil
+}
+
+func integralOfTerm(term ITerm, varname string) (ITerm, error) {
+	for _, w := range term.Vars() {
+		if _, isFunc := w.(IFunction); isFunc && dependsOn(w, varname) {
+			return nil, ErrNotIntegrable(fmt.Errorf("%s has a function of %s", term, varname))
+		}
+	}
+
+	v := term.Var(varname)
+	if nil == v {
+		vs := append(VariableList{NewVar(varname)}, term.Vars()...)
+		return newTermC(coeffOf(term), vs...), nil
+	}
+
+	if math0.IsApproxEqual(v.Power(), -1.0) {
+		abs := FuncExpr(FuncAbs, 1, newExprOf(NewTerm(1, NewVar(varname))))
+		vs := VariableList{NewFunc(FuncLog, abs)}
+		for _, w := range term.Vars() {
+			if w.Name() != varname {
+				vs = append(vs, w)
+			}
+		}
+		return newTermC(coeffOf(term), vs...), nil
+	}
+
+	vs := make(VariableList, 0, len(term.Vars()))
+	for _, v := range term.Vars() {
+		if v.Name() == varname {
+			v = v.AddPower(1)
+		}
+		vs = append(vs, v)
+	}
+	return newTermC(coeffOf(term)/complex(v.Power()+1, 0), vs...), nil
+}
+
+// _BoundValuation binds one variable and defers the rest to m.
+type _BoundValuation struct {
+	name  string
+	value float64
+	m     IValuation
+}
+
+func (this _BoundValuation) Get(varname string) (float64, bool) {
+	if varname == this.name {
+		return this.value, true
+	}
+	if nil == this.m {
+		return 0, false
+	}
+	return this.m.Get(varname)
+}
@@ -0,0 +1,82 @@
+package expr
+
+import (
+	"math"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+var ttIntegrate = []_parsetest{
+	//0
+	_parsetest{"3x^2 + 2x + 1", "1(x) + 1(x^2) + 1(x^3)"},
+	//1
+	_parsetest{"x*y^2 + y", "1(x*y) + 0.5(x^2*y^2)"},
+	//2
+	_parsetest{"3/x + x", "3(log(abs(x))) + 0.5(x^2)"},
+	//3
+	_parsetest{"x^-2 + x^0.5", "-1(x^-1) + 0.666667(x^1.5)"},
+	//4
+	_parsetest{"sin(y)*x", "0.5(sin(y)*x^2)"},
+	//5
+	_parsetest{"0", "0"},
+}
+
+func TestIntegrate(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for i, tt := range ttIntegrate {
+		e, err := Integrate(mustParse(tt.s), "x")
+		if assert.Nil(err, "i=%d", i) {
+			assert.Equal(tt.expected, e.String(), "i=%d", i)
+		}
+	}
+
+	e, err := Integrate(NewExpr(NewComplexTerm(1i, MustVars("s^3")...)), "s")
+	if assert.Nil(err) {
+		assert.Equal("(0+0.25i)(s^4)", e.String())
+	}
+
+	for _, s := range []string{"sin(x)", "x + exp(2x)", "pow(2, x)*y"} {
+		_, err := Integrate(mustParse(s), "x")
+		assert.NotNil(err, s)
+	}
+}
+
+func TestDefiniteIntegral(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e := mustParse("x*y^2 + 3x + 2")
+	v, err := DefiniteIntegral(e, "x", 0, 2, Valuation{"y": 1})
+	assert.Nil(err)
+	assert.Equal(12.0, v)
+
+	v, err = DefiniteIntegral(mustParse("1/x"), "x", 1, math.E, nil)
+	assert.Nil(err)
+	assert.InDelta(1.0, v, 1e-12)
+
+	_, err = DefiniteIntegral(e, "x", 0, 2, nil)
+	assert.NotNil(err)
+	_, err = DefiniteIntegral(mustParse("cos(x)"), "x", 0, 1, nil)
+	assert.NotNil(err)
+
+	// the antiderivative is continuous on [0, 1]
+	v, err = DefiniteIntegral(mustParse("x^-0.5"), "x", 0, 1, nil)
+	assert.Nil(err)
+	assert.InDelta(2.0, v, 1e-12)
+
+	// poles in the interval, or at an end of it
+	for _, tt := range []struct {
+		s    string
+		a, b float64
+	}{
+		{"x^-2", -1, 1},
+		{"1/x", -1, 2},
+		{"y/x + 1", 2, -1},
+		{"x^-1.5", 0, 1},
+		{"x^-2", -1, 0},
+	} {
+		_, err = DefiniteIntegral(mustParse(tt.s), "x", tt.a, tt.b, Valuation{"y": 1})
+		assert.NotNil(err, "%s from %v to %v", tt.s, tt.a, tt.b)
+	}
+}
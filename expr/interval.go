@@ -0,0 +1,366 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/noypi/math0"
+)
+
+// Interval is the closed range [Lo, Hi], the bounds may be infinite.
+type Interval struct {
+	Lo, Hi float64
+}
+
+// IntervalValuation bounds each variable to an interval, i.e. a box.
+type IntervalValuation map[string]Interval
+
+// Truth is the three valued answer of IsEquationTrueOverBox.
+type Truth int
+
+const (
+	Never Truth = iota
+	Always
+	Maybe
+)
+
+func (this IntervalValuation) Get(varname string) (iv Interval, has bool) {
+	iv, has = this[varname]
+	return
+}
+
+func (this Interval) Contains(f float64) bool {
+	return this.Lo <= f && f <= this.Hi
+}
+
+func (this Interval) String() string {
+	return fmt.Sprintf("[%v, %v]", this.Lo, this.Hi)
+}
+
+func (this Truth) String() string {
+	switch this {
+	case Never:
+		return "never"
+	case Always:
+		return "always"
+	case Maybe:
+		return "maybe"
+	}
+	return "<unknown truth>"
+}
+
+// EvalInterval returns bounds on e over the box m. The bounds are rounded
+// outward so the true range is always inside, though it may be wider than
+// the true range when a variable occurs more than once. A box where e may
+// be undefined, e.g. x^-1 over [-1, 1], gives ErrDivisionByZero or
+// ErrDomain.
+func EvalInterval(e IExpression, m IntervalValuation) (value Interval, err error) {
+	e.EachTerm(func(term ITerm) bool {
+		if isComplexCoeff(coeffOf(term)) {
+			err = ErrDomain(fmt.Errorf("%s has a complex coefficient", term))
+			return false
+		}
+		termValue := Interval{term.C(), term.C()}
+		for _, v := range term.Vars() {
+			var iv Interval
+			if iv, err = intervalOfFactor(v, m); nil != err {
+				return false
+			}
+			if iv, err = powInterval(iv, v.Power(), v); nil != err {
+				return false
+			}
+			termValue = mulInterval(termValue, iv)
+		}
+		value = addInterval(value, termValue)
+		return true
+	})
+
+	return
+}
+
+// IsEquationTrueOverBox tells whether IsEquationTrue holds at every point
+// of the box, at none, or maybe at some. Maybe is also the answer when the
+// bounds are too loose to decide.
+func IsEquationTrueOverBox(eqn IEquation, m IntervalValuation) (Truth, error) {
+	left, err := EvalInterval(eqn.Left(), m)
+	if nil != err {
+		return Maybe, err
+	}
+	right, err := EvalInterval(eqn.Right(), m)
+	if nil != err {
+		return Maybe, err
+	}
+
+	// d = left - right, compared as in Relation.Test
+	d := addInterval(left, Interval{-right.Hi, -right.Lo})
+	eps := math0.Epsilon
+	var always, never bool
+	switch eqn.Relation() {
+	case LEQ:
+		always, never = d.Hi < eps, eps <= d.Lo
+	case GEQ:
+		always, never = -eps < d.Lo, d.Hi <= -eps
+	case Lesser:
+		always, never = d.Hi < 0, 0 <= d.Lo
+	case Greater:
+		always, never = 0 < d.Lo, d.Hi <= 0
+	case EQ:
+		always, never = -eps < d.Lo && d.Hi < eps, eps <= d.Lo || d.Hi <= -eps
+	case NEQ:
+		always, never = eps <= d.Lo || d.Hi <= -eps, -eps < d.Lo && d.Hi < eps
+	}
+
+	switch {
+	case always:
+		return Always, nil
+	case never:
+		return Never, nil
+	}
+	return Maybe, nil
+}
+
+func intervalOfFactor(v IVariable, m IntervalValuation) (Interval, error) {
+	fn, isFunc := v.(IFunction)
+	if !isFunc {
+		iv, has := m.Get(v.Name())
+		if !has {
+			return Interval{}, ErrNoValuationForVar(fmt.Errorf("var=%s has no valuation", v.Name()))
+		}
+		if !(iv.Lo <= iv.Hi) {
+			return Interval{}, fmt.Errorf("var=%s has the empty interval %s", v.Name(), iv)
+		}
+		return iv, nil
+	}
+
+	args := make([]Interval, len(fn.Args()))
+	for i, arg := range fn.Args() {
+		var err error
+		if args[i], err = EvalInterval(arg, m); nil != err {
+			return Interval{}, err
+		}
+	}
+	return funcInterval(fn, args)
+}
+
+func funcInterval(fn IFunction, args []Interval) (Interval, error) {
+	u := args[0]
+	switch fn.Kind() {
+	case FuncSin:
+		// maxima at pi/2 + 2k*pi, minima at -pi/2 + 2k*pi
+		return periodicInterval(u, math.Sin, math.Pi/2), nil
+	case FuncCos:
+		return periodicInterval(u, math.Cos, 0), nil
+	case FuncTan:
+		// tan is increasing between its poles at pi/2 + k*pi
+		k := math.Floor((u.Lo + math.Pi/2) / math.Pi)
+		if u.Hi-u.Lo >= math.Pi || !(u.Hi < k*math.Pi+math.Pi/2) || math.IsInf(u.Lo, 0) {
+			return Interval{}, ErrDomain(fmt.Errorf("%s may be undefined for %s in %s", fn, fn.Args()[0], u))
+		}
+		return monotoneInterval(u, math.Tan), nil
+	case FuncExp:
+		return monotoneInterval(u, math.Exp), nil
+	case FuncLog:
+		if 0 >= u.Lo {
+			return Interval{}, ErrDomain(fmt.Errorf("%s may be undefined for %s in %s", fn, fn.Args()[0], u))
+		}
+		return monotoneInterval(u, math.Log), nil
+	case FuncSqrt:
+		if 0 > u.Lo {
+			return Interval{}, ErrDomain(fmt.Errorf("%s may be undefined for %s in %s", fn, fn.Args()[0], u))
+		}
+		return monotoneInterval(u, math.Sqrt), nil
+	case FuncAbs:
+		return absInterval(u), nil
+	case FuncPow:
+		// b^e = exp(e*log(b)) for b > 0
+		if 0 >= u.Lo {
+			return Interval{}, ErrDomain(fmt.Errorf("%s may be undefined for %s in %s", fn, fn.Args()[0], u))
+		}
+		logb := monotoneInterval(u, math.Log)
+		return monotoneInterval(mulInterval(args[1], logb), math.Exp), nil
+	}
+	return Interval{}, fmt.Errorf("unknown function %d", int(fn.Kind()))
+}
+
+// powInterval returns iv^p, v is only used in errors.
+func powInterval(iv Interval, p float64, v IVariable) (Interval, error) {
+	if math0.IsApproxEqual(p, 1.0) {
+		return iv, nil
+	}
+	if math0.IsApproxEqual(p, 0.0) {
+		return Interval{1, 1}, nil
+	}
+
+	if !isIntegral(p) {
+		if 0 > iv.Lo || (0 > p && 0 == iv.Lo) {
+			return Interval{}, ErrDomain(fmt.Errorf("%s may be undefined for %s in %s", v, v.Name(), iv))
+		}
+		if 0 > p {
+			return monotoneInterval(Interval{iv.Hi, iv.Lo}, func(f float64) float64 { return math.Pow(f, p) }), nil
+		}
+		return monotoneInterval(iv, func(f float64) float64 { return math.Pow(f, p) }), nil
+	}
+
+	n := int(math.Round(p))
+	if 0 > n {
+		if iv.Contains(0) {
+			return Interval{}, ErrDivisionByZero(fmt.Errorf("%s may be undefined for %s in %s", v, v.Name(), iv))
+		}
+		lo, _ := invRounded(iv.Hi)
+		_, hi := invRounded(iv.Lo)
+		iv, n = Interval{lo, hi}, -n
+	}
+
+	loDown, loUp := powRounded(iv.Lo, n)
+	hiDown, hiUp := powRounded(iv.Hi, n)
+	switch {
+	case 1 == n&1 || 0 <= iv.Lo:
+		return Interval{loDown, hiUp}, nil
+	case 0 >= iv.Hi:
+		return Interval{hiDown, loUp}, nil
+	}
+	// an even power of an interval around zero
+	return Interval{0, math.Max(loUp, hiUp)}, nil
+}
+
+// powRounded returns x^n rounded down and up, by repeated multiplication
+// for small n.
+func powRounded(x float64, n int) (down, up float64) {
+	if 0 > x {
+		down, up = powRounded(-x, n)
+		if 1 == n&1 {
+			down, up = -up, -down
+		}
+		return
+	}
+	if 64 < n {
+		iv := monotoneInterval(Interval{x, x}, func(f float64) float64 { return math.Pow(f, float64(n)) })
+		return math.Max(iv.Lo, 0), iv.Hi
+	}
+
+	down, up = 1, 1
+	for i := 0; i < n; i++ {
+		down, _ = mulRounded(down, x)
+		_, up = mulRounded(up, x)
+	}
+	return
+}
+
+// invRounded returns 1/x rounded down and up, x is not zero.
+func invRounded(x float64) (down, up float64) {
+	q := 1 / x
+	if math.IsInf(x, 0) {
+		return q, q
+	}
+	// 1/x - q has the sign of (1 - q*x)/x
+	r := math.FMA(-q, x, 1)
+	if 0 > x {
+		r = -r
+	}
+	return roundedBy(q, r)
+}
+
+func addInterval(a, b Interval) Interval {
+	lo, _ := addRounded(a.Lo, b.Lo)
+	_, hi := addRounded(a.Hi, b.Hi)
+	return Interval{lo, hi}
+}
+
+func mulInterval(a, b Interval) Interval {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, x := range []float64{a.Lo, a.Hi} {
+		for _, y := range []float64{b.Lo, b.Hi} {
+			down, up := mulRounded(x, y)
+			lo, hi = math.Min(lo, down), math.Max(hi, up)
+		}
+	}
+	return Interval{lo, hi}
+}
+
+// addRounded returns x + y rounded down and up. The rounding error of the
+// sum is exact (Knuth's two-sum), so exact sums are not widened.
+func addRounded(x, y float64) (down, up float64) {
+	s := x + y
+	if math.IsInf(s, 0) || math.IsNaN(s) {
+		return s, s
+	}
+	v := s - x
+	return roundedBy(s, (x-(s-v))+(y-v))
+}
+
+// mulRounded returns x*y rounded down and up, the error of the product is
+// exact by a fused multiply-add. 0*inf is taken to be 0.
+func mulRounded(x, y float64) (down, up float64) {
+	f := x * y
+	if math.IsNaN(f) {
+		return 0, 0
+	}
+	if math.IsInf(f, 0) {
+		return f, f
+	}
+	return roundedBy(f, math.FMA(x, y, -f))
+}
+
+// roundedBy returns the floats below and above f + residual.
+func roundedBy(f, residual float64) (down, up float64) {
+	down, up = f, f
+	if 0 > residual {
+		down = math.Nextafter(f, math.Inf(-1))
+	} else if 0 < residual {
+		up = math.Nextafter(f, math.Inf(1))
+	}
+	return
+}
+
+func absInterval(iv Interval) Interval {
+	switch {
+	case 0 <= iv.Lo:
+		return iv
+	case 0 >= iv.Hi:
+		return Interval{-iv.Hi, -iv.Lo}
+	}
+	return Interval{0, math.Max(-iv.Lo, iv.Hi)}
+}
+
+// monotoneInterval returns [f(iv.Lo), f(iv.Hi)] for an increasing f, pass
+// the bounds swapped for a decreasing f. The math package functions are
+// accurate to about an ulp, so a few are added on each side.
+func monotoneInterval(iv Interval, f func(float64) float64) Interval {
+	return outward(Interval{f(iv.Lo), f(iv.Hi)}, 4)
+}
+
+// periodicInterval bounds sin or cos, f has its maxima at peak + 2k*pi and
+// its minima at peak + pi + 2k*pi.
+func periodicInterval(iv Interval, f func(float64) float64, peak float64) Interval {
+	if iv.Hi-iv.Lo >= 2*math.Pi || math.IsInf(iv.Lo, 0) || math.IsInf(iv.Hi, 0) {
+		return Interval{-1, 1}
+	}
+
+	a, b := f(iv.Lo), f(iv.Hi)
+	out := outward(Interval{math.Min(a, b), math.Max(a, b)}, 4)
+	// the tolerance keeps an extremum just outside iv by rounding
+	tol := 1e-12 * (1 + math.Max(math.Abs(iv.Lo), math.Abs(iv.Hi)))
+	if hasPoint(iv, peak, tol) {
+		out.Hi = 1
+	}
+	if hasPoint(iv, peak+math.Pi, tol) {
+		out.Lo = -1
+	}
+	out.Lo, out.Hi = math.Max(out.Lo, -1), math.Min(out.Hi, 1)
+	return out
+}
+
+// hasPoint reports whether x + 2k*pi is in iv for some k, within tol.
+func hasPoint(iv Interval, x, tol float64) bool {
+	k := math.Ceil((iv.Lo - tol - x) / (2 * math.Pi))
+	return x+k*2*math.Pi <= iv.Hi+tol
+}
+
+// outward widens iv by n ulps on each side.
+func outward(iv Interval, n int) Interval {
+	for i := 0; i < n; i++ {
+		iv.Lo = math.Nextafter(iv.Lo, math.Inf(-1))
+		iv.Hi = math.Nextafter(iv.Hi, math.Inf(1))
+	}
+	return iv
+}
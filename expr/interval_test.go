@@ -0,0 +1,120 @@
+package expr
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+type _intervaltest struct {
+	e      string
+	lo, hi float64 // the true range over ttBox
+	exact  bool    // whether the bounds are tight
+}
+
+var ttBox = IntervalValuation{
+	"x": Interval{-2, 3},
+	"y": Interval{0.5, 1},
+	"z": Interval{-3, -1},
+}
+
+var ttEvalInterval = []_intervaltest{
+	//0
+	_intervaltest{"x^2", 0, 9, true},
+	//1
+	_intervaltest{"x^3", -8, 27, true},
+	//2
+	_intervaltest{"z^2", 1, 9, true},
+	//3
+	_intervaltest{"x*z", -9, 6, true},
+	//4
+	_intervaltest{"y^-1 + z^-1", 0, 1.6666666666666667, false},
+	//5
+	_intervaltest{"sin(x)", -1, 1, true},
+	//6
+	_intervaltest{"cos(x)", -0.9899924966004454, 1, true},
+	//7
+	_intervaltest{"exp(z) + sqrt(y) + log(y)", 0, 0, false},
+	//8
+	_intervaltest{"abs(x) - 2x*y", -6, 4, false},
+	//9
+	_intervaltest{"y^0.5 - 3", -2.2928932188134525, -2, true},
+}
+
+func TestEvalInterval(t *testing.T) {
+	assert := assertpkg.New(t)
+	rnd := rand.New(rand.NewSource(1))
+
+	for i, tt := range ttEvalInterval {
+		e := mustParse(tt.e)
+		iv, err := EvalInterval(e, ttBox)
+		if !assert.Nil(err, "i=%d", i) {
+			continue
+		}
+		if tt.exact {
+			assert.InDelta(tt.lo, iv.Lo, 1e-12, "i=%d", i)
+			assert.InDelta(tt.hi, iv.Hi, 1e-12, "i=%d", i)
+		}
+
+		// every point of the box is inside the bounds
+		for k := 0; k < 1000; k++ {
+			m := Valuation{}
+			for name, b := range ttBox {
+				m[name] = b.Lo + (b.Hi-b.Lo)*rnd.Float64()
+				if 0 == k%10 {
+					m[name] = b.Lo
+				}
+			}
+			v, err := ValueOfExpr(e, m)
+			assert.Nil(err, "i=%d", i)
+			assert.True(iv.Contains(v), "i=%d %s at %v is %v", i, iv, m, v)
+		}
+	}
+
+	for _, s := range []string{"x^-1", "log(x)", "sqrt(z)", "x^0.5", "tan(x)", "w"} {
+		_, err := EvalInterval(mustParse(s), ttBox)
+		assert.NotNil(err, s)
+	}
+
+	// bounds are rounded outward
+	iv, err := EvalInterval(mustParse("x + y"), IntervalValuation{"x": Interval{0.1, 0.1}, "y": Interval{0.2, 0.2}})
+	assert.Nil(err)
+	assert.True(iv.Lo < iv.Hi && iv.Hi-iv.Lo < 1e-15)
+	assert.True(iv.Contains(0.3))
+
+	// exact operations are not widened
+	iv, err = EvalInterval(mustParse("3x^2 - 2y"), IntervalValuation{"x": Interval{-1, 2}, "y": Interval{0.5, 4}})
+	assert.Nil(err)
+	assert.Equal(Interval{-8, 11}, iv)
+
+	iv, err = EvalInterval(mustParse("x^2"), IntervalValuation{"x": Interval{math.Inf(-1), 1}})
+	assert.Nil(err)
+	assert.Equal(Interval{0, math.Inf(1)}, iv)
+}
+
+func TestIsEquationTrueOverBox(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for s, expected := range map[string]Truth{
+		"x^2 + y^2 <= 10": Always,
+		"x^2 + y^2 <= 1":  Maybe,
+		"x^2 + y^2 > 20":  Never,
+		"z < 0":           Always,
+		"z >= 0":          Never,
+		"x == 5":          Never,
+		"x != 5":          Always,
+		"y*z != 0":        Always,
+		"x*y == 0":        Maybe,
+		"x*y - x <= 2":    Maybe, // x occurs twice, the bounds are loose
+	} {
+		truth, err := IsEquationTrueOverBox(mustParseEquation(s), ttBox)
+		assert.Nil(err, s)
+		assert.Equal(expected, truth, "%s is %s", s, truth)
+	}
+
+	truth, err := IsEquationTrueOverBox(mustParseEquation("x^-1 > 0"), ttBox)
+	assert.NotNil(err)
+	assert.Equal(Maybe, truth)
+}
@@ -0,0 +1,339 @@
+package expr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/noypi/math0"
+)
+
+// JSON schema of expressions, terms, variables and equations:
+//
+//	expression: {"terms": [term, ...]}
+//	term:       {"c": 3, "imag": 2, "vars": [variable, ...]}
+//	variable:   {"name": "x", "power": 2}
+//	function:   {"func": "sin", "args": [expression, ...], "power": 2}
+//	equation:   {"left": expression, "relation": "<=", "right": expression}
+//
+// "imag" is present only for complex coefficients, "vars" only when not
+// empty and "power" only when not 1. Functions are one of sin, cos, tan,
+// exp, log, sqrt, abs and pow. Relations are one of <=, >=, ==, !=, < and
+// >. The MarshalJSON methods write the relations as they are, but
+// json.Marshal escapes < and > in whatever it encodes, e.g. "\u003c=";
+// MarshalExpr and MarshalEquation, or an Encoder with SetEscapeHTML(false),
+// do not. Decoding builds with the EqnBuilder_* constructors, so that e.g.
+// kiwi variables can be decoded.
+type _JSONExpr struct {
+	Terms []_JSONTerm `json:"terms"`
+}
+
+type _JSONTerm struct {
+	C    float64    `json:"c"`
+	Imag float64    `json:"imag,omitempty"`
+	Vars []_JSONVar `json:"vars,omitempty"`
+}
+
+type _JSONVar struct {
+	Name  string      `json:"name,omitempty"`
+	Func  string      `json:"func,omitempty"`
+	Args  []_JSONExpr `json:"args,omitempty"`
+	Power *float64    `json:"power,omitempty"` // 1 if nil
+}
+
+type _JSONEquation struct {
+	Left     _JSONExpr `json:"left"`
+	Relation Relation  `json:"relation"`
+	Right    _JSONExpr `json:"right"`
+}
+
+// MarshalExpr encodes e as in the schema above, without escaping < and >.
+func MarshalExpr(e IExpression) ([]byte, error) {
+	return marshalJSON(jsonOfExpr(e))
+}
+
+// MarshalEquation encodes eqn without escaping < and >, e.g. the relation
+// as "<=" rather than "\u003c=".
+func MarshalEquation(eqn IEquation) ([]byte, error) {
+	return marshalJSON(jsonOfEquation(eqn))
+}
+
+// UnmarshalExpr decodes an expression built with EqnBuilder_ExprConstructor.
+func UnmarshalExpr(data []byte) (IExpression, error) {
+	var o _JSONExpr
+	if err := json.Unmarshal(data, &o); nil != err {
+		return nil, err
+	}
+	return o.decode()
+}
+
+// UnmarshalEquation decodes an equation, see UnmarshalExpr.
+func UnmarshalEquation(data []byte) (IEquation, error) {
+	var o _JSONEquation
+	if err := json.Unmarshal(data, &o); nil != err {
+		return nil, err
+	}
+	return o.decode()
+}
+
+// MarshalText encodes the relation as "<=", ">=", "==", "!=", "<" or ">".
+func (this Relation) MarshalText() ([]byte, error) {
+	if this < LEQ || this > Greater {
+		return nil, fmt.Errorf("expr: unknown relation %d", int(this))
+	}
+	return []byte(this.String()), nil
+}
+
+// UnmarshalText accepts what MarshalText writes, and "=".
+func (this *Relation) UnmarshalText(text []byte) error {
+	s := _Scanner{src: string(text)}
+	if tok := s.Next(); tokRelation == tok.kind && tokEOF == s.Next().kind {
+		*this = tok.relation
+		return nil
+	}
+	return fmt.Errorf("expr: unknown relation %q", text)
+}
+
+func (this _Expression) MarshalJSON() ([]byte, error) {
+	return marshalJSON(jsonOfExpr(&this))
+}
+
+func (this *_Expression) UnmarshalJSON(data []byte) error {
+	var o _JSONExpr
+	if err := json.Unmarshal(data, &o); nil != err {
+		return err
+	}
+	terms, err := o.decodeTerms()
+	if nil != err {
+		return err
+	}
+	this.SetTerms(terms...)
+	return nil
+}
+
+func (this _Term) MarshalJSON() ([]byte, error) {
+	return marshalJSON(jsonOfTerm(&this))
+}
+
+// UnmarshalJSON refuses complex coefficients, which need an IComplexTerm.
+func (this *_Term) UnmarshalJSON(data []byte) error {
+	var o _JSONTerm
+	if err := json.Unmarshal(data, &o); nil != err {
+		return err
+	}
+	if 0 != o.Imag {
+		return fmt.Errorf("expr: term has a complex coefficient")
+	}
+	vs, err := decodeVars(o.Vars)
+	if nil != err {
+		return err
+	}
+	this.SetC(o.C)
+	this.SetVars(vs...)
+	return nil
+}
+
+func (this _ComplexTerm) MarshalJSON() ([]byte, error) {
+	return marshalJSON(jsonOfTerm(&this))
+}
+
+func (this *_ComplexTerm) UnmarshalJSON(data []byte) error {
+	var o _JSONTerm
+	if err := json.Unmarshal(data, &o); nil != err {
+		return err
+	}
+	vs, err := decodeVars(o.Vars)
+	if nil != err {
+		return err
+	}
+	this.SetCC(complex(o.C, o.Imag))
+	this.SetVars(vs...)
+	return nil
+}
+
+func (this _Variable) MarshalJSON() ([]byte, error) {
+	return marshalJSON(jsonOfVar(&this))
+}
+
+func (this *_Variable) UnmarshalJSON(data []byte) error {
+	var o _JSONVar
+	if err := json.Unmarshal(data, &o); nil != err {
+		return err
+	}
+	if "" != o.Func || "" == o.Name {
+		return fmt.Errorf("expr: not a variable")
+	}
+	this.name, this.power = o.Name, o.power()
+	return nil
+}
+
+func (this _Function) MarshalJSON() ([]byte, error) {
+	return marshalJSON(jsonOfVar(&this))
+}
+
+func (this *_Function) UnmarshalJSON(data []byte) error {
+	var o _JSONVar
+	if err := json.Unmarshal(data, &o); nil != err {
+		return err
+	}
+	if "" == o.Func {
+		return fmt.Errorf("expr: not a function")
+	}
+	v, err := o.decode()
+	if nil != err {
+		return err
+	}
+	*this = *v.(*_Function)
+	return nil
+}
+
+func (this _Equation) MarshalJSON() ([]byte, error) {
+	return marshalJSON(jsonOfEquation(&this))
+}
+
+func (this *_Equation) UnmarshalJSON(data []byte) error {
+	var o _JSONEquation
+	if err := json.Unmarshal(data, &o); nil != err {
+		return err
+	}
+	eqn, err := o.decode()
+	if nil != err {
+		return err
+	}
+	*this = *eqn.(*_Equation)
+	return nil
+}
+
+// marshalJSON is json.Marshal without the escaping of <, > and &.
+func marshalJSON(v interface{}) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); nil != err {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func jsonOfEquation(eqn IEquation) _JSONEquation {
+	return _JSONEquation{
+		Left:     jsonOfExpr(eqn.Left()),
+		Relation: eqn.Relation(),
+		Right:    jsonOfExpr(eqn.Right()),
+	}
+}
+
+func jsonOfExpr(e IExpression) _JSONExpr {
+	o := _JSONExpr{Terms: []_JSONTerm{}}
+	e.EachTerm(func(term ITerm) bool {
+		o.Terms = append(o.Terms, jsonOfTerm(term))
+		return true
+	})
+	return o
+}
+
+func jsonOfTerm(term ITerm) _JSONTerm {
+	c := coeffOf(term)
+	o := _JSONTerm{C: real(c), Imag: imag(c)}
+	for _, v := range term.Vars() {
+		o.Vars = append(o.Vars, jsonOfVar(v))
+	}
+	return o
+}
+
+func jsonOfVar(v IVariable) _JSONVar {
+	var o _JSONVar
+	if fn, isFunc := v.(IFunction); isFunc {
+		o.Func = fn.Kind().String()
+		for _, arg := range fn.Args() {
+			o.Args = append(o.Args, jsonOfExpr(arg))
+		}
+	} else {
+		o.Name = v.Name()
+	}
+	if p := v.Power(); !math0.IsApproxEqual(p, 1.0) {
+		o.Power = &p
+	}
+	return o
+}
+
+func (this _JSONExpr) decode() (IExpression, error) {
+	terms, err := this.decodeTerms()
+	if nil != err {
+		return nil, err
+	}
+	return EqnBuilder_ExprConstructor(terms...), nil
+}
+
+func (this _JSONExpr) decodeTerms() (TermList, error) {
+	terms := make(TermList, 0, len(this.Terms))
+	for _, t := range this.Terms {
+		vs, err := decodeVars(t.Vars)
+		if nil != err {
+			return nil, err
+		}
+		if 0 != t.Imag {
+			terms = append(terms, NewComplexTerm(complex(t.C, t.Imag), vs...))
+		} else {
+			terms = append(terms, EqnBuilder_TermConstructor(t.C, vs...))
+		}
+	}
+	return terms, nil
+}
+
+func decodeVars(ls []_JSONVar) (VariableList, error) {
+	vs := make(VariableList, 0, len(ls))
+	for _, o := range ls {
+		v, err := o.decode()
+		if nil != err {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+func (this _JSONVar) power() float64 {
+	if nil == this.Power {
+		return 1.0
+	}
+	return *this.Power
+}
+
+func (this _JSONVar) decode() (IVariable, error) {
+	if "" == this.Func {
+		if "" == this.Name {
+			return nil, fmt.Errorf("expr: variable has no name")
+		}
+		return EqnBuilder_VarConstructor(this.Name, this.power()), nil
+	}
+
+	kind, has := g_funcNames[this.Func]
+	if !has {
+		return nil, fmt.Errorf("expr: unknown function %q", this.Func)
+	}
+	if kind.Arity() != len(this.Args) {
+		return nil, fmt.Errorf("expr: %s takes %d arguments, found %d", kind, kind.Arity(), len(this.Args))
+	}
+	args := make([]IExpression, 0, len(this.Args))
+	for _, a := range this.Args {
+		arg, err := a.decode()
+		if nil != err {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return NewFuncN(kind, this.power(), args...), nil
+}
+
+func (this _JSONEquation) decode() (IEquation, error) {
+	left, err := this.Left.decode()
+	if nil != err {
+		return nil, err
+	}
+	right, err := this.Right.decode()
+	if nil != err {
+		return nil, err
+	}
+	return Equation(left, this.Relation, right), nil
+}
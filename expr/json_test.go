@@ -0,0 +1,121 @@
+package expr
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+var ttJSON = []_parsetest{
+	//0
+	_parsetest{"3x^2 - 4y + 5", `{"terms":[{"c":5},{"c":3,"vars":[{"name":"x","power":2}]},{"c":-4,"vars":[{"name":"y"}]}]}`},
+	//1
+	_parsetest{"sin(x)^2*y", `{"terms":[{"c":1,"vars":[{"func":"sin","args":[{"terms":[{"c":1,"vars":[{"name":"x"}]}]}],"power":2},{"name":"y"}]}]}`},
+	//2
+	_parsetest{"0", `{"terms":[]}`},
+}
+
+func TestJSON_Expression(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for i, tt := range ttJSON {
+		e := mustParse(tt.s)
+		bb, err := json.Marshal(e)
+		assert.Nil(err, "i=%d", i)
+		assert.Equal(tt.expected, string(bb), "i=%d", i)
+
+		e2, err := UnmarshalExpr(bb)
+		assert.Nil(err, "i=%d", i)
+		assert.Equal(e.String(), e2.String(), "i=%d", i)
+
+		e3 := NewExpr()
+		assert.Nil(json.Unmarshal(bb, e3), "i=%d", i)
+		assert.Equal(e.String(), e3.String(), "i=%d", i)
+	}
+
+	for _, s := range []string{
+		"pow(x+1, 0.5) + pow(x, y) + exp(abs(x)) - log(sqrt(x)) + tan(cos(x))",
+		"1.5e-7x*y^-3",
+	} {
+		e := mustParse(s)
+		bb, err := json.Marshal(e)
+		assert.Nil(err, s)
+		e2, err := UnmarshalExpr(bb)
+		assert.Nil(err, s)
+		assert.Equal(e.String(), e2.String(), s)
+	}
+
+	for _, s := range []string{
+		`{"terms":[{"c":1,"vars":[{}]}]}`,
+		`{"terms":[{"c":1,"vars":[{"func":"sinh","args":[]}]}]}`,
+		`{"terms":[{"c":1,"vars":[{"func":"pow","args":[{"terms":[]}]}]}]}`,
+		`{"terms":{}}`,
+	} {
+		_, err := UnmarshalExpr([]byte(s))
+		assert.NotNil(err, s)
+	}
+}
+
+func TestJSON_Complex(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e := NewExpr(NewComplexTerm(complex(1, -2), NewVar("s")), NewTerm(3))
+	bb, err := json.Marshal(e)
+	assert.Nil(err)
+	assert.Equal(`{"terms":[{"c":3},{"c":1,"imag":-2,"vars":[{"name":"s"}]}]}`, string(bb))
+
+	e2, err := UnmarshalExpr(bb)
+	assert.Nil(err)
+	assert.Equal(e.String(), e2.String())
+	ct, isComplex := e2.Terms()[1].(IComplexTerm)
+	assert.True(isComplex)
+	assert.Equal(complex(1, -2), ct.CC())
+
+	// a real term cannot hold a complex coefficient
+	assert.NotNil(json.Unmarshal([]byte(`{"c":1,"imag":2}`), NewTerm(0)))
+}
+
+func TestJSON_Equation(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for _, s := range []string{"x + 2y <= 3", "x >= y", "x^2 == 4", "x != 0", "x < 1", "x > sin(y)"} {
+		eqn := mustParseEquation(s)
+		bb, err := json.Marshal(eqn)
+		assert.Nil(err, s)
+
+		eqn2, err := UnmarshalEquation(bb)
+		assert.Nil(err, s)
+		assert.Equal(eqn.String(), eqn2.String(), s)
+	}
+
+	bb, err := MarshalEquation(mustParseEquation("x <= 3"))
+	assert.Nil(err)
+	assert.Equal(`{"left":{"terms":[{"c":1,"vars":[{"name":"x"}]}]},"relation":"<=","right":{"terms":[{"c":3}]}}`, string(bb))
+	eqn, err := UnmarshalEquation(bb)
+	if assert.Nil(err) {
+		assert.Equal("1(x) <= 3", eqn.String())
+	}
+	bb, err = MarshalExpr(mustParse("x - 1"))
+	assert.Nil(err)
+	assert.Equal(`{"terms":[{"c":-1},{"c":1,"vars":[{"name":"x"}]}]}`, string(bb))
+
+	// json.Marshal escapes what MarshalJSON writes, an Encoder need not
+	bb, err = json.Marshal(mustParseEquation("x <= 3"))
+	assert.Nil(err)
+	assert.Contains(string(bb), `"relation":"\u003c="`)
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	assert.Nil(enc.Encode([]IEquation{mustParseEquation("x > 1")}))
+	assert.Contains(buf.String(), `"relation":">"`)
+
+	var rel Relation
+	assert.Nil(json.Unmarshal([]byte(`"="`), &rel))
+	assert.Equal(EQ, rel)
+	assert.NotNil(json.Unmarshal([]byte(`"=<"`), &rel))
+	assert.NotNil(json.Unmarshal([]byte(`"<= 1"`), &rel))
+	_, err = json.Marshal(Relation(42))
+	assert.NotNil(err)
+}
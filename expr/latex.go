@@ -0,0 +1,245 @@
+package expr
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/noypi/math0"
+)
+
+var g_latexGreek = map[string]bool{
+	"alpha": true, "beta": true, "gamma": true, "delta": true, "epsilon": true,
+	"zeta": true, "eta": true, "theta": true, "iota": true, "kappa": true,
+	"lambda": true, "mu": true, "nu": true, "xi": true, "pi": true, "rho": true,
+	"sigma": true, "tau": true, "upsilon": true, "phi": true, "chi": true,
+	"psi": true, "omega": true,
+	"Gamma": true, "Delta": true, "Theta": true, "Lambda": true, "Xi": true,
+	"Pi": true, "Sigma": true, "Phi": true, "Psi": true, "Omega": true,
+}
+
+// ToLaTeX renders e for reports, e.g. 3(x^2) + -4(y) + 1(x*y) as
+// "3x^{2} - 4y + x \cdot y".
+func ToLaTeX(e IExpression) string {
+	if 0 == len(e.Terms()) {
+		return "0"
+	}
+
+	buf := bytes.NewBufferString("")
+	e.EachTerm(func(term ITerm) bool {
+		s := termLaTeX(term)
+		isNeg := strings.HasPrefix(s, "-")
+		switch {
+		case 0 == buf.Len():
+			buf.WriteString(s)
+		case isNeg:
+			buf.WriteString(" - ")
+			buf.WriteString(s[1:])
+		default:
+			buf.WriteString(" + ")
+			buf.WriteString(s)
+		}
+		return true
+	})
+	return buf.String()
+}
+
+// EquationToLaTeX renders eqn, e.g. "3x^{2} - 4y \leq 5".
+func EquationToLaTeX(eqn IEquation) string {
+	return ToLaTeX(eqn.Left()) + " " + eqn.Relation().LaTeX() + " " + ToLaTeX(eqn.Right())
+}
+
+// LaTeX is the relation as a LaTeX operator.
+func (this Relation) LaTeX() string {
+	switch this {
+	case LEQ:
+		return `\leq`
+	case GEQ:
+		return `\geq`
+	case EQ:
+		return "="
+	case NEQ:
+		return `\neq`
+	case Lesser:
+		return "<"
+	case Greater:
+		return ">"
+	}
+	return `\,?\,`
+}
+
+// termLaTeX renders term with a leading "-" if it is negative.
+func termLaTeX(term ITerm) string {
+	vs := term.Vars()
+	factors := make([]string, 0, len(vs))
+	for _, v := range vs {
+		factors = append(factors, varLaTeX(v))
+	}
+	product := strings.Join(factors, ` \cdot `)
+
+	if ct, isComplex := term.(IComplexTerm); isComplex && isComplexCoeff(ct.CC()) {
+		return complexLaTeX(ct.CC(), product)
+	}
+
+	c := term.C()
+	sign := ""
+	if 0 > c {
+		sign, c = "-", -c
+	}
+	coeff := numberLaTeX(c)
+	switch {
+	case 0 == len(product):
+		return sign + coeff
+	case math0.IsApproxEqual(c, 1.0):
+		return sign + product
+	case startsWithDigit(product) || strings.Contains(coeff, `\times`):
+		return sign + coeff + ` \cdot ` + product
+	}
+	return sign + coeff + product
+}
+
+func complexLaTeX(c complex128, product string) string {
+	coeff := ""
+	if math0.IsApproxEqual(real(c), 0.0) {
+		// a pure imaginary coefficient, the sign is left in front
+		coeff = numberLaTeX(imag(c)) + "i"
+		if 0 == len(product) {
+			return coeff
+		}
+		return coeff + " " + product
+	}
+
+	sign := " + "
+	if 0 > imag(c) {
+		sign = " - "
+	}
+	coeff = `\left(` + numberLaTeX(real(c)) + sign + numberLaTeX(math.Abs(imag(c))) + `i\right)`
+	if 0 == len(product) {
+		return coeff
+	}
+	return coeff + " " + product
+}
+
+func varLaTeX(v IVariable) string {
+	base := ""
+	fn, isFunc := v.(IFunction)
+	bGroup := isFunc && needsGroup(fn, v.Power())
+	switch {
+	case bGroup:
+		base = funcLaTeX(fn, 1)
+		if !math0.IsApproxEqual(v.Power(), 1.0) {
+			base = `\left(` + base + `\right)`
+		}
+	case isFunc:
+		base = funcLaTeX(fn, v.Power())
+	default:
+		base = nameLaTeX(v.Name())
+	}
+
+	if math0.IsApproxEqual(v.Power(), 1.0) || (isFunc && !bGroup) {
+		return base
+	}
+	return base + "^{" + numberLaTeX(v.Power()) + "}"
+}
+
+// needsGroup reports whether the power of fn is written after the whole of
+// fn, rather than after the function name as in \sin^{2}\left(x\right).
+// Only positive integral powers go after the name, as \sin^{-1} is arcsin.
+func needsGroup(fn IFunction, power float64) bool {
+	switch fn.Kind() {
+	case FuncSin, FuncCos, FuncTan, FuncLog, FuncExp:
+		return !isIntegral(power) || 0 > power
+	}
+	return true
+}
+
+func funcLaTeX(fn IFunction, power float64) string {
+	args := fn.Args()
+	u := ToLaTeX(args[0])
+
+	name := ""
+	switch fn.Kind() {
+	case FuncSin:
+		name = `\sin`
+	case FuncCos:
+		name = `\cos`
+	case FuncTan:
+		name = `\tan`
+	case FuncLog:
+		name = `\ln`
+	case FuncExp:
+		name = `\exp`
+	case FuncSqrt:
+		return `\sqrt{` + u + `}`
+	case FuncAbs:
+		return `\left|` + u + `\right|`
+	case FuncPow:
+		if !isAtomLaTeX(args[0]) {
+			u = `\left(` + u + `\right)`
+		}
+		return u + "^{" + ToLaTeX(args[1]) + "}"
+	}
+
+	if !math0.IsApproxEqual(power, 1.0) {
+		name += "^{" + numberLaTeX(power) + "}"
+	}
+	return name + `\left(` + u + `\right)`
+}
+
+// nameLaTeX renders a variable name: greek letters as commands, text after
+// the first underscore or trailing digits as a subscript and longer names
+// upright, e.g. x_1 and x1 as x_{1}, alpha as \alpha.
+func nameLaTeX(name string) string {
+	sub := ""
+	if i := strings.Index(name, "_"); 0 < i && i < len(name)-1 {
+		name, sub = name[:i], name[i+1:]
+	} else if i := strings.TrimRight(name, "0123456789"); 0 < len(i) && len(i) < len(name) {
+		name, sub = i, name[len(i):]
+	}
+
+	switch {
+	case g_latexGreek[name]:
+		name = `\` + name
+	case 1 < utf8.RuneCountInString(name):
+		name = `\mathrm{` + strings.Replace(name, "_", `\_`, -1) + `}`
+	}
+	if "" != sub {
+		name += "_{" + strings.Replace(sub, "_", `\_`, -1) + "}"
+	}
+	return name
+}
+
+// numberLaTeX writes exponents as powers of ten, e.g. 1.5 \times 10^{-7}.
+func numberLaTeX(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	i := strings.IndexByte(s, 'e')
+	if 0 > i {
+		return s
+	}
+	exp, _ := strconv.Atoi(s[i+1:])
+	return s[:i] + ` \times 10^{` + strconv.Itoa(exp) + "}"
+}
+
+// isAtomLaTeX reports whether e needs no parentheses as a base, i.e. it is
+// a nonnegative number or a lone variable.
+func isAtomLaTeX(e IExpression) bool {
+	terms := e.Terms()
+	switch {
+	case 0 == len(terms):
+		return true
+	case 1 < len(terms):
+		return false
+	}
+	vs := terms[0].Vars()
+	if 0 == len(vs) {
+		return 0 <= terms[0].C()
+	}
+	_, isFunc := vs[0].(IFunction)
+	return 1 == len(vs) && !isFunc && math0.IsApproxEqual(terms[0].C(), 1.0) && math0.IsApproxEqual(vs[0].Power(), 1.0)
+}
+
+func startsWithDigit(s string) bool {
+	return 0 < len(s) && isDigit(s[0])
+}
@@ -0,0 +1,58 @@
+package expr
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+var ttLaTeX = []_parsetest{
+	//0
+	_parsetest{"3x^2 - 4y", "3x^{2} - 4y"},
+	//1
+	_parsetest{"-x + 2x*y - 1", `-1 - x + 2x \cdot y`},
+	//2
+	_parsetest{"0", "0"},
+	//3
+	_parsetest{"x^-1 + y^0.5", "x^{-1} + y^{0.5}"},
+	//4
+	_parsetest{"x_1 + x2 + alpha + theta_0 + speed", `\alpha + \mathrm{speed} + \theta_{0} + x_{2} + x_{1}`},
+	//5
+	_parsetest{"3sin(x*y)^2 - exp(-z)", `-\exp\left(-z\right) + 3\sin^{2}\left(x \cdot y\right)`},
+	//6
+	_parsetest{"sqrt(x + 1) + abs(x)^2 + log(x)", `\left(\left|x\right|\right)^{2} + \ln\left(x\right) + \sqrt{1 + x}`},
+	//7
+	_parsetest{"x^y + 2^x + (x + 1)^y", `\left(1 + x\right)^{y} + 2^{x} + x^{y}`},
+	//8
+	_parsetest{"0.0000015x - 2.5", `-2.5 + 1.5 \times 10^{-6} \cdot x`},
+	//9
+	_parsetest{"sin(x)^-1 + cos(x)^0.5", `\left(\cos\left(x\right)\right)^{0.5} + \left(\sin\left(x\right)\right)^{-1}`},
+	//10
+	_parsetest{"y/tan(x)^2", `\left(\tan\left(x\right)\right)^{-2} \cdot y`},
+}
+
+func TestToLaTeX(t *testing.T) {
+	assert := assertpkg.New(t)
+	for i, tt := range ttLaTeX {
+		assert.Equal(tt.expected, ToLaTeX(mustParse(tt.s)), "i=%d", i)
+	}
+
+	s := MustVars("s")
+	e := NewExpr(NewComplexTerm(1-2i, s...), NewComplexTerm(-3i), NewTerm(-1, MustVars("s^2")...))
+	assert.Equal(`-3i + \left(1 - 2i\right) s - s^{2}`, ToLaTeX(e))
+}
+
+func TestEquationToLaTeX(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for rel, expected := range map[string]string{
+		"<=": `3x^{2} - 4y \leq 5`,
+		">=": `3x^{2} - 4y \geq 5`,
+		"==": `3x^{2} - 4y = 5`,
+		"!=": `3x^{2} - 4y \neq 5`,
+		"<":  `3x^{2} - 4y < 5`,
+		">":  `3x^{2} - 4y > 5`,
+	} {
+		assert.Equal(expected, EquationToLaTeX(mustParseEquation("3x^2 - 4y "+rel+" 5")), rel)
+	}
+}
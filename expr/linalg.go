@@ -0,0 +1,53 @@
+package expr
+
+import (
+	"math"
+)
+
+// solveDense solves a*x = b by Gaussian elimination with partial pivoting.
+// a and b are overwritten. ok is false if a is singular.
+func solveDense(a [][]float64, b []float64) (x []float64, ok bool) {
+	n := len(b)
+	scale := 0.0
+	for _, row := range a {
+		for _, f := range row {
+			scale = math.Max(scale, math.Abs(f))
+		}
+	}
+	eps := 1e-12 * math.Max(scale, 1)
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for i := col + 1; i < n; i++ {
+			if math.Abs(a[i][col]) > math.Abs(a[pivot][col]) {
+				pivot = i
+			}
+		}
+		if math.Abs(a[pivot][col]) <= eps {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+
+		for i := col + 1; i < n; i++ {
+			f := a[i][col] / a[col][col]
+			if 0 == f {
+				continue
+			}
+			for j := col; j < n; j++ {
+				a[i][j] -= f * a[col][j]
+			}
+			b[i] -= f * b[col]
+		}
+	}
+
+	x = make([]float64, n)
+	for i := n - 1; 0 <= i; i-- {
+		sum := b[i]
+		for j := i + 1; j < n; j++ {
+			sum -= a[i][j] * x[j]
+		}
+		x[i] = sum / a[i][i]
+	}
+	return x, true
+}
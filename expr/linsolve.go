@@ -0,0 +1,209 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/noypi/math0"
+)
+
+type SolutionKind int
+
+const (
+	SolutionUnique SolutionKind = iota
+	SolutionParametric
+	SolutionInconsistent
+)
+
+// Solution of a linear system. Values has every variable of the system,
+// as a constant if the solution is unique, otherwise in terms of the Free
+// variables, which are mapped to themselves. An inconsistent system has
+// no Values, Inconsistent is then the indices of equations that cannot
+// hold together.
+type Solution struct {
+	Kind         SolutionKind
+	Values       map[string]IExpression
+	Free         []string
+	Inconsistent []int
+}
+
+func (this SolutionKind) String() string {
+	switch this {
+	case SolutionUnique:
+		return "unique"
+	case SolutionParametric:
+		return "parametric"
+	case SolutionInconsistent:
+		return "inconsistent"
+	}
+	return "<unknown solution kind>"
+}
+
+func (this Solution) String() string {
+	buf := bytes.NewBufferString(this.Kind.String())
+	if SolutionInconsistent == this.Kind {
+		buf.WriteString(fmt.Sprintf(", equations %v", this.Inconsistent))
+		return buf.String()
+	}
+
+	names := make([]string, 0, len(this.Values))
+	for name := range this.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if 0 == i {
+			buf.WriteString(": ")
+		} else {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(fmt.Sprintf("%s = %s", name, this.Values[name]))
+	}
+	return buf.String()
+}
+
+// SolveLinearSystem solves EQ equations linear in their variables by
+// Gauss-Jordan elimination with partial pivoting. Variables are eliminated
+// in sorted order, so the free variables of a parametric solution are the
+// later ones. A nonlinear equation is an ErrNotLinear.
+func SolveLinearSystem(eqns []IEquation) (Solution, error) {
+	fs := make([]IExpression, len(eqns))
+	for i, eqn := range eqns {
+		if EQ != eqn.Relation() {
+			return Solution{}, fmt.Errorf("linear: equation %d (%s) is not an equality", i, eqn)
+		}
+		fs[i] = Sub(eqn.Left(), eqn.Right())
+	}
+	vars := varNames(fs...)
+	index := make(map[string]int, len(vars))
+	for j, name := range vars {
+		index[name] = j
+	}
+
+	// each row is the coefficients, the right side, then the combination
+	// of the equations it was reduced from
+	n, m := len(vars), len(fs)
+	rows := make([][]float64, m)
+	scale := 0.0
+	for i, f := range fs {
+		row := make([]float64, n+1+m)
+		row[n+1+i] = 1
+		var err error
+		f.EachTerm(func(term ITerm) bool {
+			if isComplexCoeff(coeffOf(term)) {
+				err = ErrDomain(fmt.Errorf("linear: %s has a complex coefficient", term))
+				return false
+			}
+			vs := term.Vars()
+			switch {
+			case 0 == len(vs):
+				row[n] -= term.C()
+			case !isLinearVar(vs):
+				err = ErrNotLinear(fmt.Errorf("linear: equation %d (%s) is not linear in %s", i, eqns[i], term))
+				return false
+			default:
+				row[index[vs[0].Name()]] += term.C()
+			}
+			scale = math.Max(scale, math.Abs(term.C()))
+			return true
+		})
+		if nil != err {
+			return Solution{}, err
+		}
+		rows[i] = row
+	}
+	eps := 1e-10 * math.Max(scale, 1)
+
+	pivots := make([]int, 0, n)
+	for col := 0; col < n && len(pivots) < m; col++ {
+		r := len(pivots)
+		p := r
+		for i := r + 1; i < m; i++ {
+			if math.Abs(rows[i][col]) > math.Abs(rows[p][col]) {
+				p = i
+			}
+		}
+		if math.Abs(rows[p][col]) <= eps {
+			continue
+		}
+		rows[r], rows[p] = rows[p], rows[r]
+
+		pivot := rows[r][col]
+		for j := range rows[r] {
+			rows[r][j] /= pivot
+		}
+		for i := range rows {
+			f := rows[i][col]
+			if i == r || 0 == f {
+				continue
+			}
+			for j := range rows[i] {
+				rows[i][j] -= f * rows[r][j]
+			}
+			rows[i][col] = 0
+		}
+		pivots = append(pivots, col)
+	}
+
+	// the rows below the pivots reduce to 0 == rhs
+	var worst []int
+	for _, row := range rows[len(pivots):] {
+		if math.Abs(row[n]) <= eps {
+			continue
+		}
+		if offending := combinationOf(row[n+1:]); nil == worst || len(offending) < len(worst) {
+			worst = offending
+		}
+	}
+	if nil != worst {
+		return Solution{Kind: SolutionInconsistent, Inconsistent: worst}, nil
+	}
+
+	o := Solution{Kind: SolutionUnique, Values: make(map[string]IExpression, n)}
+	isPivot := make([]bool, n)
+	for _, col := range pivots {
+		isPivot[col] = true
+	}
+	for j, name := range vars {
+		if !isPivot[j] {
+			o.Kind = SolutionParametric
+			o.Free = append(o.Free, name)
+			o.Values[name] = newExprOf(EqnBuilder_TermConstructor(1, EqnBuilder_VarConstructor(name, 1)))
+		}
+	}
+	for r, col := range pivots {
+		terms := TermList{EqnBuilder_TermConstructor(rows[r][n])}
+		for j, name := range vars {
+			if f := rows[r][j]; !isPivot[j] && math.Abs(f) > eps {
+				terms = append(terms, EqnBuilder_TermConstructor(-f, EqnBuilder_VarConstructor(name, 1)))
+			}
+		}
+		o.Values[vars[col]] = newExprOf(terms...)
+	}
+	return o, nil
+}
+
+func isLinearVar(vs VariableList) bool {
+	if 1 != len(vs) {
+		return false
+	}
+	_, isFunc := vs[0].(IFunction)
+	return !isFunc && math0.IsApproxEqual(vs[0].Power(), 1.0)
+}
+
+// combinationOf returns the indices of the equations with a part in a
+// combination.
+func combinationOf(weights []float64) (out []int) {
+	largest := 0.0
+	for _, w := range weights {
+		largest = math.Max(largest, math.Abs(w))
+	}
+	for i, w := range weights {
+		if math.Abs(w) > 1e-9*largest {
+			out = append(out, i)
+		}
+	}
+	return
+}
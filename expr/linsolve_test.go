@@ -0,0 +1,106 @@
+package expr
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func mustParseEquations(ss ...string) []IEquation {
+	eqns := make([]IEquation, len(ss))
+	for i, s := range ss {
+		eqns[i] = mustParseEquation(s)
+	}
+	return eqns
+}
+
+func TestSolveLinearSystem(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	eqns := mustParseEquations(
+		"2x + y - z == 8",
+		"-3x - y + 2z == -11",
+		"-2x + y + 2z == -3",
+	)
+	sol, err := SolveLinearSystem(eqns)
+	if assert.Nil(err) {
+		assert.Equal(SolutionUnique, sol.Kind)
+		assert.Nil(sol.Free)
+		for name, expected := range map[string]float64{"x": 2, "y": 3, "z": -1} {
+			c, isConst := ConstantOf(sol.Values[name])
+			assert.True(isConst, name)
+			assert.InDelta(expected, c, 1e-12, name)
+		}
+	}
+
+	// a zero pivot in the first column, and a redundant equation
+	sol, err = SolveLinearSystem(mustParseEquations("y == 1", "x + y == 3", "2x + 2y == 6"))
+	if assert.Nil(err) {
+		assert.Equal("unique: x = 2, y = 1", sol.String())
+	}
+}
+
+func TestSolveLinearSystem_Parametric(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	eqns := mustParseEquations(
+		"x + y + z == 6",
+		"x - y == 0",
+		"2x + z == 6",
+	)
+	sol, err := SolveLinearSystem(eqns)
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Equal(SolutionParametric, sol.Kind)
+	assert.Equal([]string{"z"}, sol.Free)
+	assert.Equal("parametric: x = 3 + -0.5(z), y = 3 + -0.5(z), z = 1(z)", sol.String())
+
+	// every member of the family solves the system
+	for _, z := range []float64{-2, 0, 7.5} {
+		m := Valuation{"z": z}
+		for _, name := range []string{"x", "y"} {
+			m[name], err = ValueOfExpr(sol.Values[name], Valuation{"z": z})
+			assert.Nil(err)
+		}
+		residuals, err := Residuals(eqns, m)
+		assert.Nil(err)
+		for _, r := range residuals {
+			assert.InDelta(0.0, r, 1e-12)
+		}
+	}
+
+	// underdetermined
+	sol, err = SolveLinearSystem(mustParseEquations("a + 2b - c == 4"))
+	if assert.Nil(err) {
+		assert.Equal([]string{"b", "c"}, sol.Free)
+		assert.Equal("4 + -2(b) + 1(c)", sol.Values["a"].String())
+	}
+}
+
+func TestSolveLinearSystem_Inconsistent(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	sol, err := SolveLinearSystem(mustParseEquations(
+		"x + y == 1",
+		"z == 4",
+		"x - y == 0",
+		"2x + 2y == 3",
+	))
+	if assert.Nil(err) {
+		assert.Equal(SolutionInconsistent, sol.Kind)
+		assert.Equal([]int{0, 3}, sol.Inconsistent)
+		assert.Nil(sol.Values)
+		assert.Equal("inconsistent, equations [0 3]", sol.String())
+	}
+
+	sol, err = SolveLinearSystem(mustParseEquations("x == 1", "x == x + 1"))
+	if assert.Nil(err) {
+		assert.Equal([]int{1}, sol.Inconsistent)
+	}
+
+	for _, s := range []string{"x*y == 1", "x^2 == 1", "sin(x) == 0", "x <= 1"} {
+		_, err := SolveLinearSystem(mustParseEquations("x + y == 1", s))
+		assert.NotNil(err, s)
+	}
+}
@@ -0,0 +1,187 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+)
+
+type NewtonStatus int
+
+const (
+	NewtonConverged NewtonStatus = iota
+	NewtonMaxIterations
+	NewtonSingularJacobian
+	NewtonLineSearchFailed
+)
+
+type NewtonOptions struct {
+	MaxIterations int     // defaults to 50
+	Tolerance     float64 // largest accepted |left - right|, defaults to 1e-10
+}
+
+// NewtonError is returned by SolveNonlinear when it does not converge.
+type NewtonError struct {
+	Status     NewtonStatus
+	Iterations int
+	Values     map[string]float64 // the last iterate
+	Residuals  []float64          // left - right of each equation at Values
+}
+
+func (this NewtonStatus) String() string {
+	switch this {
+	case NewtonConverged:
+		return "converged"
+	case NewtonMaxIterations:
+		return "maximum iterations reached"
+	case NewtonSingularJacobian:
+		return "singular jacobian"
+	case NewtonLineSearchFailed:
+		return "line search failed"
+	}
+	return "<unknown newton status>"
+}
+
+func (this NewtonError) Error() string {
+	worst := 0.0
+	for _, r := range this.Residuals {
+		worst = math.Max(worst, math.Abs(r))
+	}
+	return fmt.Sprintf("newton: %s after %d iterations, max residual=%g", this.Status, this.Iterations, worst)
+}
+
+// Residuals returns left - right of each equation under m.
+func Residuals(eqns []IEquation, m IValuation) ([]float64, error) {
+	out := make([]float64, len(eqns))
+	for i, eqn := range eqns {
+		left, err := ValueOfExpr(eqn.Left(), m)
+		if nil != err {
+			return nil, err
+		}
+		right, err := ValueOfExpr(eqn.Right(), m)
+		if nil != err {
+			return nil, err
+		}
+		out[i] = left - right
+	}
+	return out, nil
+}
+
+// SolveNonlinear solves a square system of EQ equations with Newton-Raphson,
+// starting from initial. The jacobian is derived symbolically and each step
+// is damped by a backtracking line search on the squared residuals. opts
+// may be nil. On failure the error is a *NewtonError.
+func SolveNonlinear(eqns []IEquation, initial IValuation, opts *NewtonOptions) (map[string]float64, error) {
+	maxIter, tol := 50, 1e-10
+	if nil != opts {
+		if 0 < opts.MaxIterations {
+			maxIter = opts.MaxIterations
+		}
+		if 0 < opts.Tolerance {
+			tol = opts.Tolerance
+		}
+	}
+
+	fs := make([]IExpression, len(eqns))
+	for i, eqn := range eqns {
+		if EQ != eqn.Relation() {
+			return nil, fmt.Errorf("newton: equation %d (%s) is not an equality", i, eqn)
+		}
+		fs[i] = Sub(eqn.Left(), eqn.Right())
+	}
+	vars := varNames(fs...)
+	if len(vars) != len(fs) {
+		return nil, fmt.Errorf("newton: %d equations in %d unknowns", len(fs), len(vars))
+	}
+
+	jacobian := make([][]IExpression, len(fs))
+	for i, f := range fs {
+		jacobian[i] = Gradient(f, vars)
+	}
+
+	x := make(Valuation, len(vars))
+	for _, name := range vars {
+		f, has := initial.Get(name)
+		if !has {
+			return nil, ErrNoValuationForVar(fmt.Errorf("var=%s has no initial value", name))
+		}
+		x[name] = f
+	}
+
+	failed := func(status NewtonStatus, iter int, residuals []float64) error {
+		return &NewtonError{Status: status, Iterations: iter, Values: x, Residuals: residuals}
+	}
+
+	residuals, err := valuesOf(fs, x)
+	if nil != err {
+		return nil, err
+	}
+	for iter := 0; ; iter++ {
+		if maxAbs(residuals) <= tol {
+			return x, nil
+		}
+		if maxIter <= iter {
+			return nil, failed(NewtonMaxIterations, iter, residuals)
+		}
+
+		a := make([][]float64, len(fs))
+		for i := range jacobian {
+			if a[i], err = valuesOf(jacobian[i], x); nil != err {
+				return nil, err
+			}
+		}
+		b := make([]float64, len(residuals))
+		for i, r := range residuals {
+			b[i] = -r
+		}
+		step, ok := solveDense(a, b)
+		if !ok {
+			return nil, failed(NewtonSingularJacobian, iter, residuals)
+		}
+
+		// backtrack until the squared residuals decrease enough (Armijo)
+		merit := sumOfSquares(residuals)
+		bAccepted := false
+		for t := 1.0; t > 1e-10; t /= 2 {
+			next := make(Valuation, len(vars))
+			for i, name := range vars {
+				next[name] = x[name] + t*step[i]
+			}
+			nextResiduals, err := valuesOf(fs, next)
+			if nil != err || sumOfSquares(nextResiduals) > (1-1e-4*t)*merit {
+				continue
+			}
+			x, residuals, bAccepted = next, nextResiduals, true
+			break
+		}
+		if !bAccepted {
+			return nil, failed(NewtonLineSearchFailed, iter, residuals)
+		}
+	}
+}
+
+func valuesOf(es []IExpression, m IValuation) (out []float64, err error) {
+	out = make([]float64, len(es))
+	for i, e := range es {
+		if out[i], err = ValueOfExpr(e, m); nil != err {
+			return nil, err
+		}
+		if math.IsNaN(out[i]) || math.IsInf(out[i], 0) {
+			return nil, fmt.Errorf("newton: %s is not finite", e)
+		}
+	}
+	return
+}
+
+func maxAbs(fs []float64) (n float64) {
+	for _, f := range fs {
+		n = math.Max(n, math.Abs(f))
+	}
+	return
+}
+
+func sumOfSquares(fs []float64) (n float64) {
+	for _, f := range fs {
+		n += f * f
+	}
+	return
+}
@@ -0,0 +1,76 @@
+package expr
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func mustParseEquation(s string) IEquation {
+	eqn, err := ParseEquation(s)
+	if nil != err {
+		panic(err)
+	}
+	return eqn
+}
+
+func TestSolveNonlinear(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	eqns := []IEquation{
+		mustParseEquation("x^2*y == 4"),
+		mustParseEquation("x + y == 3"),
+	}
+	sol, err := SolveNonlinear(eqns, Valuation{"x": -2, "y": 5}, nil)
+	if assert.Nil(err) {
+		assert.InDelta(-1.0, sol["x"], 1e-9)
+		assert.InDelta(4.0, sol["y"], 1e-9)
+
+		residuals, err := Residuals(eqns, Valuation(sol))
+		assert.Nil(err)
+		assert.InDelta(0.0, residuals[0], 1e-9)
+		assert.InDelta(0.0, residuals[1], 1e-9)
+	}
+
+	// (2, 1) is a double root, where convergence is only linear
+	sol, err = SolveNonlinear(eqns, Valuation{"x": 3, "y": 0.5}, nil)
+	if assert.Nil(err) {
+		assert.InDelta(2.0, sol["x"], 1e-4)
+		assert.InDelta(1.0, sol["y"], 1e-4)
+	}
+
+	// circle and line
+	eqns = []IEquation{
+		mustParseEquation("x^2 + y^2 = 25"),
+		mustParseEquation("y = x + 1"),
+	}
+	sol, err = SolveNonlinear(eqns, Valuation{"x": 1, "y": 1}, &NewtonOptions{Tolerance: 1e-12})
+	if assert.Nil(err) {
+		assert.InDelta(3.0, sol["x"], 1e-9)
+		assert.InDelta(4.0, sol["y"], 1e-9)
+	}
+}
+
+func TestSolveNonlinear_Failures(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// no real solution
+	eqns := []IEquation{mustParseEquation("x^2 + 1 == 0")}
+	_, err := SolveNonlinear(eqns, Valuation{"x": 1}, nil)
+	if nerr, ok := err.(*NewtonError); assert.True(ok, "%v", err) {
+		assert.NotEqual(NewtonConverged, nerr.Status)
+		assert.Equal(1, len(nerr.Residuals))
+	}
+
+	_, err = SolveNonlinear(eqns, Valuation{"x": 0}, nil)
+	if nerr, ok := err.(*NewtonError); assert.True(ok, "%v", err) {
+		assert.Equal(NewtonSingularJacobian, nerr.Status)
+	}
+
+	_, err = SolveNonlinear([]IEquation{mustParseEquation("x + y == 1")}, Valuation{"x": 0, "y": 0}, nil)
+	assert.NotNil(err)
+	_, err = SolveNonlinear([]IEquation{mustParseEquation("x <= 1")}, Valuation{"x": 0}, nil)
+	assert.NotNil(err)
+	_, err = SolveNonlinear([]IEquation{mustParseEquation("x == 1")}, Valuation{}, nil)
+	assert.NotNil(err)
+}
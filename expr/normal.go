@@ -0,0 +1,60 @@
+package expr
+
+import (
+	"math/cmplx"
+)
+
+// Normalize moves everything in eqn to the left and scales it so that the
+// leading term, the first of the highest degree, has coefficient 1. The
+// relation is flipped if it is scaled by a negative number, e.g. both
+// "2x <= 4" and "4 >= 2x" become "-2 + 1(x) <= 0", and "y >= x" becomes
+// "1(x) + -1(y) <= 0".
+func Normalize(eqn IEquation) IEquation {
+	f := Sub(eqn.Left(), eqn.Right())
+	rel := eqn.Relation()
+
+	var leading ITerm
+	f.EachTerm(func(term ITerm) bool {
+		if nil == leading || term.PowerTotal() > leading.PowerTotal() {
+			leading = term
+		}
+		return true
+	})
+	if nil == leading {
+		return Equation(f, rel, nil)
+	}
+
+	c := coeffOf(leading)
+	if isComplexCoeff(c) && EQ != rel && NEQ != rel {
+		// only scaled to a magnitude of 1, as a complex factor would not
+		// keep the relation
+		c = complex(cmplx.Abs(c), 0)
+	}
+	if 0 > real(c) && !isComplexCoeff(c) {
+		rel = rel.Flip()
+	}
+
+	terms := cloneTerms(f)
+	for i, term := range terms {
+		terms[i] = withCoeff(term, coeffOf(term)/c)
+	}
+	return Equation(newExprOf(terms...), rel, nil)
+}
+
+// EqualExpr reports whether the coefficients of a and b differ by at most
+// tol, term by term.
+func EqualExpr(a, b IExpression, tol float64) bool {
+	bEqual := true
+	Sub(a, b).EachTerm(func(term ITerm) bool {
+		bEqual = cmplx.Abs(coeffOf(term)) <= tol
+		return bEqual
+	})
+	return bEqual
+}
+
+// EquivalentEquations reports whether a and b are the same equation up to
+// rearranging and scaling, i.e. whether they normalize to the same one.
+func EquivalentEquations(a, b IEquation) bool {
+	na, nb := Normalize(a), Normalize(b)
+	return na.Relation() == nb.Relation() && EqualExpr(na.Left(), nb.Left(), 1e-9)
+}
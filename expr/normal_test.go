@@ -0,0 +1,74 @@
+package expr
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+var ttNormalize = []_parsetest{
+	//0
+	_parsetest{"2x <= 4", "-2 + 1(x) <= 0"},
+	//1
+	_parsetest{"4 >= 2x", "-2 + 1(x) <= 0"},
+	//2
+	_parsetest{"y >= x", "1(x) + -1(y) <= 0"},
+	//3
+	_parsetest{"3 - x > 2y", "-3 + 1(x) + 2(y) < 0"},
+	//4
+	_parsetest{"-2x^2 + x == 4", "2 + -0.5(x) + 1(x^2) == 0"},
+	//5
+	_parsetest{"x*y - 4sin(x) != x*y", "1(sin(x)) != 0"},
+	//6
+	_parsetest{"1 <= 2", "1 >= 0"},
+	//7
+	_parsetest{"x == x", "0 == 0"},
+}
+
+func TestNormalize(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for i, tt := range ttNormalize {
+		eqn := Normalize(mustParseEquation(tt.s))
+		assert.Equal(tt.expected, eqn.String(), "i=%d", i)
+	}
+
+	// complex coefficients
+	eqn := Normalize(Equation(NewExpr(NewComplexTerm(2i, NewVar("s"))), EQ, NewExpr(NewTerm(4))))
+	assert.Equal("(0+2i) + 1(s) == 0", eqn.String())
+}
+
+func TestEquivalentEquations(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for _, ss := range [][2]string{
+		{"2x <= 4", "x - 2 <= 0"},
+		{"y >= x", "x <= y"},
+		{"3x + 6y == 9", "-x - 2y + 3 == 0"},
+		{"x/3 < 1", "x < 3"},
+		{"x != 2y", "-4y != -2x"},
+		{"sin(x)^2 >= 1", "-sin(x)^2 <= -1"},
+	} {
+		assert.True(EquivalentEquations(mustParseEquation(ss[0]), mustParseEquation(ss[1])), "%v", ss)
+	}
+
+	for _, ss := range [][2]string{
+		{"2x <= 4", "x - 2 >= 0"},
+		{"x <= y", "x < y"},
+		{"x == y", "x <= y"},
+		{"x + y == 1", "x + y == 1.001"},
+		{"x^2 <= 1", "x <= 1"},
+	} {
+		assert.False(EquivalentEquations(mustParseEquation(ss[0]), mustParseEquation(ss[1])), "%v", ss)
+	}
+}
+
+func TestEqualExpr(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	assert.True(EqualExpr(mustParse("(x + 1)*2"), mustParse("2x + 2"), 0))
+	assert.True(EqualExpr(mustParse("x + 0.1"), mustParse("x + 0.10001"), 1e-4))
+	assert.False(EqualExpr(mustParse("x + 0.1"), mustParse("x + 0.10001"), 1e-6))
+	assert.False(EqualExpr(mustParse("x"), mustParse("y"), 0.5))
+	assert.True(EqualExpr(mustParse("x"), mustParse("1.2x"), 0.5))
+}
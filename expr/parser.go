@@ -0,0 +1,269 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// grammar:
+//
+//	equation := sum relation sum
+//	sum      := product { ("+" | "-") product }
+//	product  := unary { ("*" | "/") unary | power }   // juxtaposition multiplies
+//	unary    := ("+" | "-") unary | power
+//	power    := primary [ "^" unary ]                 // right associative
+//	primary  := number | identifier | call | "(" sum ")"
+//	call     := function "(" sum { "," sum } ")"       // e.g. sin(x), pow(x, y)
+type _Parser struct {
+	scanner _Scanner
+	tok     _Token
+}
+
+// ParseExpr parses s, e.g. "3x^2 + 2*x*y - 5", into an expression built
+// with the EqnBuilder_* constructors. Errors are of type *ParseError.
+func ParseExpr(s string) (IExpression, error) {
+	p := newParser(s)
+	e, err := p.parseSum()
+	if nil != err {
+		return nil, err
+	}
+	if err = p.expect(tokEOF); nil != err {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ParseEquation parses s, e.g. "3x^2 + 2*x*y - 5 <= 4y", into an equation.
+// The relations are <=, >=, == (or =), !=, < and >.
+func ParseEquation(s string) (IEquation, error) {
+	p := newParser(s)
+	left, err := p.parseSum()
+	if nil != err {
+		return nil, err
+	}
+	if tokRelation != p.tok.kind {
+		return nil, p.unexpected(p.tok, tokRelation.String())
+	}
+	rel := p.tok.relation
+	p.next()
+
+	right, err := p.parseSum()
+	if nil != err {
+		return nil, err
+	}
+	if err = p.expect(tokEOF); nil != err {
+		return nil, err
+	}
+	return Equation(left, rel, right), nil
+}
+
+func newParser(s string) *_Parser {
+	p := &_Parser{scanner: _Scanner{src: s}}
+	p.next()
+	return p
+}
+
+func (this *_Parser) next() {
+	this.tok = this.scanner.Next()
+}
+
+func (this *_Parser) errorf(tok _Token, format string, args ...interface{}) error {
+	return newParseError(this.scanner.src, tok, fmt.Sprintf(format, args...))
+}
+
+func (this *_Parser) unexpected(tok _Token, expected ...string) error {
+	return newParseError(this.scanner.src, tok, "unexpected "+tok.String(), expected...)
+}
+
+func (this *_Parser) expect(kind _TokenKind) error {
+	if kind != this.tok.kind {
+		return this.unexpected(this.tok, kind.String())
+	}
+	return nil
+}
+
+func (this *_Parser) parseSum() (e IExpression, err error) {
+	if e, err = this.parseProduct(); nil != err {
+		return
+	}
+
+	for tokPlus == this.tok.kind || tokMinus == this.tok.kind {
+		op := this.tok.kind
+		this.next()
+
+		var rhs IExpression
+		if rhs, err = this.parseProduct(); nil != err {
+			return
+		}
+		if tokMinus == op {
+			rhs = Neg(rhs)
+		}
+		e = Add(e, rhs)
+	}
+	return
+}
+
+func (this *_Parser) parseProduct() (e IExpression, err error) {
+	if e, err = this.parseUnary(); nil != err {
+		return
+	}
+
+	for {
+		var rhs IExpression
+		switch this.tok.kind {
+		case tokStar:
+			this.next()
+			if rhs, err = this.parseUnary(); nil != err {
+				return
+			}
+			e = Mul(e, rhs)
+
+		case tokSlash:
+			opTok := this.tok
+			this.next()
+			if rhs, err = this.parseUnary(); nil != err {
+				return
+			}
+			if isZeroExpr(rhs) {
+				return nil, this.errorf(opTok, "division by zero")
+			}
+			var inverse IExpression
+			if inverse, err = Pow(rhs, -1); nil != err {
+				return nil, this.errorf(opTok, "division by an expression of several terms is not supported")
+			}
+			e = Mul(e, inverse)
+
+		case tokIdent, tokLParen:
+			// implicit multiplication, e.g. "3x" or "2(x+1)"
+			if rhs, err = this.parsePower(); nil != err {
+				return
+			}
+			e = Mul(e, rhs)
+
+		default:
+			return
+		}
+	}
+}
+
+func (this *_Parser) parseUnary() (IExpression, error) {
+	switch this.tok.kind {
+	case tokMinus:
+		this.next()
+		e, err := this.parseUnary()
+		if nil != err {
+			return nil, err
+		}
+		return Neg(e), nil
+
+	case tokPlus:
+		this.next()
+		return this.parseUnary()
+	}
+
+	return this.parsePower()
+}
+
+func (this *_Parser) parsePower() (e IExpression, err error) {
+	if e, err = this.parsePrimary(); nil != err {
+		return
+	}
+	if tokCaret != this.tok.kind {
+		return
+	}
+
+	opTok := this.tok
+	this.next()
+	var exponent IExpression
+	if exponent, err = this.parseUnary(); nil != err {
+		return
+	}
+	n, isConst := ConstantOf(exponent)
+	if !isConst {
+		return newExprOf(EqnBuilder_TermConstructor(1, NewFunc(FuncPow, e, exponent))), nil
+	}
+
+	var out IExpression
+	if out, err = PowReal(e, n); nil != err {
+		return nil, this.errorf(opTok, "unsupported exponent %s: %v", ToTrimZero(n), err)
+	}
+	return out, nil
+}
+
+func (this *_Parser) parsePrimary() (IExpression, error) {
+	tok := this.tok
+	switch tok.kind {
+	case tokNumber:
+		c, err := strconv.ParseFloat(tok.text, 64)
+		if nil != err {
+			return nil, this.errorf(tok, "invalid number %s", tok)
+		}
+		this.next()
+		return newExprOf(EqnBuilder_TermConstructor(c)), nil
+
+	case tokIdent:
+		this.next()
+		if kind, isFunc := g_funcNames[tok.text]; isFunc && tokLParen == this.tok.kind {
+			return this.parseCall(tok, kind)
+		}
+		v := EqnBuilder_VarConstructor(tok.text, 1.0)
+		return newExprOf(EqnBuilder_TermConstructor(1.0, v)), nil
+
+	case tokLParen:
+		this.next()
+		e, err := this.parseSum()
+		if nil != err {
+			return nil, err
+		}
+		if err = this.expect(tokRParen); nil != err {
+			return nil, err
+		}
+		this.next()
+		return e, nil
+	}
+
+	return nil, this.unexpected(tok, tokNumber.String(), tokIdent.String(), tokLParen.String())
+}
+
+func (this *_Parser) parseCall(nameTok _Token, kind FuncKind) (IExpression, error) {
+	this.next() // '('
+
+	var args []IExpression
+	bConst := true
+	for {
+		arg, err := this.parseSum()
+		if nil != err {
+			return nil, err
+		}
+		_, isConst := ConstantOf(arg)
+		bConst = bConst && isConst
+		args = append(args, arg)
+
+		if tokComma != this.tok.kind {
+			break
+		}
+		this.next()
+	}
+	if err := this.expect(tokRParen); nil != err {
+		return nil, err
+	}
+	this.next()
+
+	if kind.Arity() != len(args) {
+		return nil, this.errorf(nameTok, "%s takes %d arguments, found %d", kind, kind.Arity(), len(args))
+	}
+
+	if !bConst {
+		return newExprOf(EqnBuilder_TermConstructor(1, NewFunc(kind, args...))), nil
+	}
+
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		values[i], _ = ConstantOf(arg)
+	}
+	c, err := kind.Apply(values...)
+	if nil != err {
+		return nil, this.errorf(nameTok, "%s", err)
+	}
+	return newExprOf(EqnBuilder_TermConstructor(c)), nil
+}
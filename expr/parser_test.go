@@ -0,0 +1,180 @@
+package expr
+
+import (
+	"math"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+type _parsetest struct {
+	s        string
+	expected string
+}
+
+var ttParseExpr = []_parsetest{
+	//0
+	_parsetest{"3x^2 + 2*x*y - 5", "-5 + 2(x*y) + 3(x^2)"},
+	//1
+	_parsetest{"x - -2", "2 + 1(x)"},
+	//2
+	_parsetest{"x_1 + 2x2", "2(x2) + 1(x_1)"},
+	//3
+	_parsetest{"(x+1)^2", "1 + 2(x) + 1(x^2)"},
+	//4
+	_parsetest{"(x+1)(x-1)", "-1 + 1(x^2)"},
+	//5
+	_parsetest{"2(x+y)/4", "0.5(x) + 0.5(y)"},
+	//6
+	_parsetest{"-x^2", "-1(x^2)"},
+	//7
+	_parsetest{"x - x + x", "1(x)"},
+	//8
+	_parsetest{"2^3^2", "512"},
+	//9
+	_parsetest{"1.5e1x y", "15(x*y)"},
+	//10
+	_parsetest{"3x/(2y^2)", "1.5(x*y^-2)"},
+	//11
+	_parsetest{"x^-2 + x^0.5 + 1/x", "1(x^-1) + 1(x^-2) + 1(x^0.5)"},
+	//12
+	_parsetest{"x^-1*x", "1"},
+}
+
+var ttParseEquation = []_parsetest{
+	//0
+	_parsetest{"3x^2 + 2*x*y - 5 <= 4y", "-5 + 2(x*y) + 3(x^2) <= 4(y)"},
+	//1
+	_parsetest{"x >= 2", "1(x) >= 2"},
+	//2
+	_parsetest{"x = 2", "1(x) == 2"},
+	//3
+	_parsetest{"x == 2", "1(x) == 2"},
+	//4
+	_parsetest{"x != y", "1(x) != 1(y)"},
+	//5
+	_parsetest{"x < 1", "1(x) < 1"},
+	//6
+	_parsetest{"x > y", "1(x) > 1(y)"},
+}
+
+func TestParseExpr(t *testing.T) {
+	assert := assertpkg.New(t)
+	for i, tt := range ttParseExpr {
+		e, err := ParseExpr(tt.s)
+		if assert.Nil(err, "i=%d", i) {
+			assert.Equal(tt.expected, e.String(), "i=%d", i)
+		}
+	}
+
+	for _, s := range []string{"3x^", "(x+1", "x/(y+1)", "x/0", "x y)", "1 <= x"} {
+		_, err := ParseExpr(s)
+		assert.NotNil(err, "s=%q", s)
+	}
+}
+
+func TestParseEquation(t *testing.T) {
+	assert := assertpkg.New(t)
+	for i, tt := range ttParseEquation {
+		eqn, err := ParseEquation(tt.s)
+		if assert.Nil(err, "i=%d", i) {
+			assert.Equal(tt.expected, eqn.String(), "i=%d", i)
+		}
+	}
+
+	for _, s := range []string{"x", "x <= ", "x < y < z"} {
+		_, err := ParseEquation(s)
+		assert.NotNil(err, "s=%q", s)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	_, err := ParseExpr("3x +\n  2y^")
+	perr, ok := err.(*ParseError)
+	if assert.True(ok) {
+		assert.Equal(10, perr.Offset)
+		assert.Equal(2, perr.Line)
+		assert.Equal(6, perr.Column)
+		assert.Equal("", perr.Token)
+		assert.Equal([]string{"number", "identifier", "'('"}, perr.Expected)
+		assert.Equal("expr: 2:6: unexpected end of input, expected number, identifier or '('", perr.Error())
+	}
+
+	_, err = Vars("x", "x^abc")
+	perr, ok = err.(*ParseError)
+	if assert.True(ok) {
+		assert.Equal("x^abc", perr.Source)
+		assert.Equal(2, perr.Offset)
+		assert.Equal("abc", perr.Token)
+		assert.Equal([]string{"number"}, perr.Expected)
+	}
+
+	_, err = Terms("3x", "3x^")
+	assert.IsType(&ParseError{}, err)
+
+	assert.Panics(func() { MustTerms("3x^") })
+}
+
+func TestParseExpr_FractionalPower(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// (x^2)^0.5 is abs(x), not x
+	e := mustParse("(x^2)^0.5")
+	assert.Equal("1(abs(x))", e.String())
+	for _, x := range []float64{-2, 0, 3} {
+		v, err := ValueOfExpr(e, Valuation{"x": x})
+		assert.Nil(err)
+		assert.Equal(math.Abs(x), v)
+	}
+
+	// a sum under a fractional power is a pow node, ((x-1)^2)^0.5 is
+	// abs(x - 1)
+	e = mustParse("((x-1)^2)^0.5")
+	assert.Equal("1(pow(1 + -2*x + x^2, 0.5))", e.String())
+	for _, x := range []float64{-2, 1, 3} {
+		v, err := ValueOfExpr(e, Valuation{"x": x})
+		assert.Nil(err)
+		assert.Equal(math.Abs(x-1), v)
+	}
+	e = mustParse("(x + 1)^1.5")
+	v, err := ValueOfExpr(e, Valuation{"x": 3})
+	assert.Nil(err)
+	assert.Equal(8.0, v)
+	_, err = ValueOfExpr(e, Valuation{"x": -2})
+	assert.NotNil(err)
+	v, err = ValueOfExpr(Derivative(e, "x"), Valuation{"x": 3})
+	assert.Nil(err)
+	assert.InDelta(3.0, v, 1e-12)
+
+	e = mustParse("(4x^2*y^3)^0.5")
+	v, err = ValueOfExpr(e, Valuation{"x": -1, "y": 4})
+	assert.Nil(err)
+	assert.Equal(16.0, v)
+	_, err = ValueOfExpr(e, Valuation{"x": -1, "y": -4})
+	assert.NotNil(err)
+}
+
+func TestParseExpr_FunctionPower(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// a function under a fractional power stays a function node
+	e := mustParse("sin(x)^0.5*y")
+	_, isFunc := e.Terms()[0].Vars()[0].(IFunction)
+	assert.True(isFunc)
+
+	m := Valuation{"x": 1, "y": 2}
+	v, err := ValueOfExpr(e, m)
+	if assert.Nil(err) {
+		assert.InDelta(2*math.Sqrt(math.Sin(1)), v, 1e-12)
+	}
+	v, err = ValueOfExpr(Derivative(e, "x"), m)
+	if assert.Nil(err) {
+		assert.InDelta(math.Cos(1)/math.Sqrt(math.Sin(1)), v, 1e-12)
+	}
+	f, err := Compile(e, []string{"x", "y"})
+	if assert.Nil(err) {
+		assert.InDelta(2*math.Sqrt(math.Sin(1)), f([]float64{1, 2}), 1e-12)
+	}
+}
@@ -0,0 +1,371 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/noypi/math0"
+)
+
+// DivMod divides a by b, so that a == q*b + r. Terms are ordered
+// lexicographically with varname first, then the other variables by name;
+// for polynomials in varname alone this is the usual long division, and in
+// general no term of r is divisible by the leading term of b.
+func DivMod(a, b IExpression, varname string) (q, r IExpression, err error) {
+	if err = checkPolynomial(a); nil != err {
+		return
+	}
+	if err = checkPolynomial(b); nil != err {
+		return
+	}
+
+	order := newLexOrder(varname, a, b)
+	lb := order.Leading(b)
+	if nil == lb {
+		err = ErrDivisionByZero(fmt.Errorf("divisor is zero"))
+		return
+	}
+	bRest := withoutTerm(b, lb)
+
+	var qTerms, rTerms TermList
+	for p := a; ; {
+		lt := order.Leading(p)
+		if nil == lt {
+			break
+		}
+
+		// the leading term is dropped explicitly, rather than trusting the
+		// subtraction to cancel it
+		pRest := withoutTerm(p, lt)
+		if m := divTerm(lt, lb); nil != m {
+			qTerms = append(qTerms, m)
+			p = Sub(pRest, Mul(newExprOf(m.Clone()), bRest))
+		} else {
+			rTerms = append(rTerms, lt.Clone())
+			p = pRest
+		}
+	}
+
+	return newExprOf(qTerms...), newExprOf(rTerms...), nil
+}
+
+// GCD returns the greatest common divisor of the polynomials a and b. With
+// integer coefficients the result keeps the integer gcd of the contents and
+// has a positive leading coefficient, otherwise it is monic. Complex
+// coefficients are an ErrDomain.
+func GCD(a, b IExpression) (IExpression, error) {
+	for _, e := range []IExpression{a, b} {
+		if err := checkPolynomial(e); nil != err {
+			return nil, err
+		}
+		if err := CheckReal(e); nil != err {
+			return nil, err
+		}
+	}
+	return normalizeGCD(gcdOf(a, b)), nil
+}
+
+func gcdOf(a, b IExpression) IExpression {
+	if isZeroExpr(a) {
+		return b
+	}
+	if isZeroExpr(b) {
+		return a
+	}
+
+	names := varNames(a, b)
+	if 0 == len(names) {
+		ca, _ := ConstantOf(a)
+		cb, _ := ConstantOf(b)
+		return newExprOf(NewTerm(gcdOfNumbers(ca, cb)))
+	}
+
+	// primitive polynomial remainder sequence in the first variable, the
+	// contents are coefficients in the remaining ones
+	x := names[0]
+	ca, cb := contentIn(a, x), contentIn(b, x)
+	c := gcdOf(ca, cb)
+
+	pa, pb := exactDiv(a, ca, x), exactDiv(b, cb, x)
+	if degreeIn(pa, x) < degreeIn(pb, x) {
+		pa, pb = pb, pa
+	}
+	for !isZeroExpr(pb) {
+		pa, pb = pb, primitivePart(pseudoRem(pa, pb, x), x)
+	}
+
+	if 0 == degreeIn(pa, x) {
+		return c
+	}
+	return Mul(c, primitivePart(pa, x))
+}
+
+func normalizeGCD(g IExpression) IExpression {
+	lt := newLexOrder("", g).Leading(g)
+	if nil == lt {
+		return g
+	}
+
+	bIntegral := true
+	g.EachTerm(func(term ITerm) bool {
+		bIntegral = isIntegral(term.C())
+		return bIntegral
+	})
+	if bIntegral {
+		if 0 > lt.C() {
+			return Neg(g)
+		}
+		return g
+	}
+	return scaleExpr(g, 1/lt.C())
+}
+
+func gcdOfNumbers(a, b float64) float64 {
+	a, b = math.Abs(a), math.Abs(b)
+	if math0.IsApproxEqual(a, 0.0) {
+		return b
+	}
+	if math0.IsApproxEqual(b, 0.0) {
+		return a
+	}
+	if !isIntegral(a) || !isIntegral(b) {
+		return 1
+	}
+
+	m, n := int64(math.Round(a)), int64(math.Round(b))
+	for 0 != n {
+		m, n = n, m%n
+	}
+	return float64(m)
+}
+
+func isIntegral(f float64) bool {
+	return math.Abs(f) < 1<<53 && math0.IsApproxEqual(f, math.Round(f))
+}
+
+// pseudoRem returns the pseudo-remainder of a by b in x, computed without
+// dividing coefficients.
+func pseudoRem(a, b IExpression, x string) IExpression {
+	db := degreeIn(b, x)
+	lcb := coeffIn(b, x, db)
+	bRest := termsBelow(b, x, db)
+
+	r := a
+	e := degreeIn(a, x) - db + 1
+	for !isZeroExpr(r) && db <= degreeIn(r, x) {
+		dr := degreeIn(r, x)
+		lcr := coeffIn(r, x, dr)
+		xk := newExprOf(NewTerm(1, NewVarN(x, float64(dr-db))))
+		if dr == db {
+			xk = newExprOf(NewTerm(1))
+		}
+		r = Sub(Mul(lcb, termsBelow(r, x, dr)), Mul(Mul(lcr, xk), bRest))
+		e--
+	}
+	scale, _ := Pow(lcb, e) // e >= 0
+	return Mul(scale, r)
+}
+
+// contentIn is the gcd of the coefficients of p as a polynomial in x.
+func contentIn(p IExpression, x string) IExpression {
+	content := newExprOf()
+	for k := degreeIn(p, x); 0 <= k; k-- {
+		if c := coeffIn(p, x, k); !isZeroExpr(c) {
+			content = gcdOf(content, c)
+		}
+	}
+	return content
+}
+
+func primitivePart(p IExpression, x string) IExpression {
+	if isZeroExpr(p) {
+		return p
+	}
+	return exactDiv(p, contentIn(p, x), x)
+}
+
+// exactDiv divides p by a known factor c.
+func exactDiv(p, c IExpression, x string) IExpression {
+	if k, isConst := ConstantOf(c); isConst {
+		return scaleExpr(p, 1/k)
+	}
+	q, _, _ := DivMod(p, c, x)
+	return q
+}
+
+// coeffIn returns the coefficient of x^k in p.
+func coeffIn(p IExpression, x string, k int) IExpression {
+	var terms TermList
+	p.EachTerm(func(term ITerm) bool {
+		if k == degreeOfTerm(term, x) {
+			vs := make(VariableList, 0, len(term.Vars()))
+			for _, v := range term.Vars() {
+				if v.Name() != x {
+					vs = append(vs, v)
+				}
+			}
+			terms = append(terms, newTermC(coeffOf(term), vs...))
+		}
+		return true
+	})
+	return newExprOf(terms...)
+}
+
+// termsBelow returns the terms of p whose degree in x is below k.
+func termsBelow(p IExpression, x string, k int) IExpression {
+	var terms TermList
+	p.EachTerm(func(term ITerm) bool {
+		if degreeOfTerm(term, x) < k {
+			terms = append(terms, term.Clone())
+		}
+		return true
+	})
+	return newExprOf(terms...)
+}
+
+func degreeIn(p IExpression, x string) (n int) {
+	p.EachTerm(func(term ITerm) bool {
+		if k := degreeOfTerm(term, x); n < k && !isZeroCoeff(coeffOf(term)) {
+			n = k
+		}
+		return true
+	})
+	return
+}
+
+func degreeOfTerm(term ITerm, x string) int {
+	if v := term.Var(x); nil != v {
+		return int(math.Round(v.Power()))
+	}
+	return 0
+}
+
+func checkPolynomial(e IExpression) (err error) {
+	e.EachTerm(func(term ITerm) bool {
+		for _, v := range term.Vars() {
+			if _, isFunc := v.(IFunction); isFunc || 0 > v.Power() || !isIntegral(v.Power()) {
+				err = ErrNotPolynomial(fmt.Errorf("%s is not a polynomial term", term))
+				return false
+			}
+		}
+		return true
+	})
+	return
+}
+
+func isZeroExpr(e IExpression) bool {
+	bZero := true
+	e.EachTerm(func(term ITerm) bool {
+		bZero = isZeroCoeff(coeffOf(term))
+		return bZero
+	})
+	return bZero
+}
+
+// varNames returns the sorted names of the variables in es, including those
+// in function arguments.
+func varNames(es ...IExpression) []string {
+	seen := map[string]bool{}
+	var names []string
+	var collect func(e IExpression)
+	collect = func(e IExpression) {
+		e.EachTerm(func(term ITerm) bool {
+			for _, v := range term.Vars() {
+				if fn, isFunc := v.(IFunction); isFunc {
+					for _, arg := range fn.Args() {
+						collect(arg)
+					}
+				} else if !seen[v.Name()] {
+					seen[v.Name()] = true
+					names = append(names, v.Name())
+				}
+			}
+			return true
+		})
+	}
+	for _, e := range es {
+		collect(e)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func withoutTerm(e IExpression, t ITerm) IExpression {
+	var terms TermList
+	e.EachTerm(func(term ITerm) bool {
+		if term.Key() != t.Key() {
+			terms = append(terms, term.Clone())
+		}
+		return true
+	})
+	return newExprOf(terms...)
+}
+
+// divTerm returns num/den, or nil if den does not divide num.
+func divTerm(num, den ITerm) ITerm {
+	for _, v := range den.Vars() {
+		if w := num.Var(v.Name()); nil == w || w.Power() < v.Power()-math0.Epsilon {
+			return nil
+		}
+	}
+
+	vs := make(VariableList, 0, len(num.Vars()))
+	for _, v := range num.Vars() {
+		if w := den.Var(v.Name()); nil == w {
+			vs = append(vs, v)
+		} else if !math0.IsApproxEqual(v.Power(), w.Power()) {
+			vs = append(vs, v.AddPower(-w.Power()))
+		}
+	}
+	return newTermC(coeffOf(num)/coeffOf(den), vs...)
+}
+
+// _LexOrder compares terms by their powers of each name in turn.
+type _LexOrder []string
+
+func newLexOrder(first string, es ...IExpression) _LexOrder {
+	var order _LexOrder
+	if 0 < len(first) {
+		order = append(order, first)
+	}
+	for _, name := range varNames(es...) {
+		if name != first {
+			order = append(order, name)
+		}
+	}
+	return order
+}
+
+func (this _LexOrder) Compare(a, b ITerm) int {
+	for _, name := range this {
+		pa, pb := 0.0, 0.0
+		if v := a.Var(name); nil != v {
+			pa = v.Power()
+		}
+		if v := b.Var(name); nil != v {
+			pb = v.Power()
+		}
+		if !math0.IsApproxEqual(pa, pb) {
+			if pa < pb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Leading returns the greatest nonzero term of e, or nil.
+func (this _LexOrder) Leading(e IExpression) (lt ITerm) {
+	e.EachTerm(func(term ITerm) bool {
+		if isZeroCoeff(coeffOf(term)) {
+			return true
+		}
+		if nil == lt || 0 < this.Compare(term, lt) {
+			lt = term
+		}
+		return true
+	})
+	return
+}
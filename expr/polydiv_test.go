@@ -0,0 +1,71 @@
+package expr
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+type _divmodtest struct {
+	a, b    string
+	varname string
+	q, r    string
+}
+
+var ttDivMod = []_divmodtest{
+	//0
+	_divmodtest{"x^3 - 2x^2 - 4", "x - 3", "x", "3 + 1(x) + 1(x^2)", "5"},
+	//1
+	_divmodtest{"x^2 - 1", "x + 1", "x", "-1 + 1(x)", "0"},
+	//2
+	_divmodtest{"x^2*y + x*y^2 + y^2", "x*y - 1", "x", "1(x) + 1(y)", "1(x) + 1(y) + 1(y^2)"},
+	//3
+	_divmodtest{"2x + 1", "4", "x", "0.25 + 0.5(x)", "0"},
+	//4
+	_divmodtest{"x + 1", "x^2", "x", "0", "1 + 1(x)"},
+}
+
+func TestDivMod(t *testing.T) {
+	assert := assertpkg.New(t)
+	for i, tt := range ttDivMod {
+		a, b := mustParse(tt.a), mustParse(tt.b)
+		q, r, err := DivMod(a, b, tt.varname)
+		if assert.Nil(err, "i=%d", i) {
+			assert.Equal(tt.q, q.String(), "q @ i=%d", i)
+			assert.Equal(tt.r, r.String(), "r @ i=%d", i)
+			assert.Equal(a.String(), Add(Mul(q, b), r).String(), "a @ i=%d", i)
+		}
+	}
+
+	_, _, err := DivMod(mustParse("x"), mustParse("0"), "x")
+	assert.NotNil(err)
+	_, _, err = DivMod(mustParse("x^1.5"), mustParse("x"), "x")
+	assert.NotNil(err)
+}
+
+var ttGCD = [][3]string{
+	//0
+	{"x^2 - 1", "x^2 + 2x + 1", "1 + 1(x)"},
+	//1
+	{"6x + 6", "4x + 4", "2 + 2(x)"},
+	//2
+	{"x^2*y - y", "x*y + y", "1(x*y) + 1(y)"},
+	//3
+	{"x^2 + 1", "x + 1", "1"},
+	//4
+	{"-x^3 + x", "0", "-1(x) + 1(x^3)"},
+	//5
+	{"(x - y)^2*(x + 2)", "(x - y)*(x + 3)", "1(x) + -1(y)"},
+	//6
+	{"12", "18", "6"},
+}
+
+func TestGCD(t *testing.T) {
+	assert := assertpkg.New(t)
+	for i, tt := range ttGCD {
+		g, err := GCD(mustParse(tt[0]), mustParse(tt[1]))
+		if assert.Nil(err, "i=%d", i) {
+			assert.Equal(tt[2], g.String(), "i=%d", i)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/noypi/math0"
+)
+
+type TermOrder int
+
+const (
+	OrderByKey        TermOrder = iota // as stored, the constant first
+	OrderByDegreeDesc                  // highest total power first, e.g. 3x^2 + x - 1
+	OrderByDegreeAsc
+)
+
+// Printer formats expressions for people. The zero Printer writes exact
+// shortest coefficients, e.g. "x^2 + -4*y + 5" for 1(x^2) + -4(y) + 5.
+type Printer struct {
+	Digits      int       // significant digits of numbers, 0 for the shortest exact form
+	ImplicitMul bool      // "3x^2 y" rather than "3*x^2*y"
+	Unicode     bool      // superscript powers, e.g. "x²"
+	FoldSigns   bool      // "x - 4y" rather than "x + -4y"
+	Order       TermOrder // the order of the terms of an expression
+	Grouped     bool      // terms as coefficient(vars), the layout of String()
+}
+
+var g_superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴',
+	'5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+	'-': '⁻', '+': '⁺',
+}
+
+func (this Printer) Expr(e IExpression) string {
+	terms := append(TermList{}, e.Terms()...)
+	if 0 == len(terms) {
+		return "0"
+	}
+	switch this.Order {
+	case OrderByDegreeDesc:
+		sort.SliceStable(terms, func(i, j int) bool { return terms[i].PowerTotal() > terms[j].PowerTotal() })
+	case OrderByDegreeAsc:
+		sort.SliceStable(terms, func(i, j int) bool { return terms[i].PowerTotal() < terms[j].PowerTotal() })
+	}
+
+	buf := bytes.NewBufferString("")
+	for i, term := range terms {
+		s := this.Term(term)
+		switch {
+		case 0 == i:
+		case this.FoldSigns && strings.HasPrefix(s, "-"):
+			buf.WriteString(" - ")
+			s = s[1:]
+		default:
+			buf.WriteString(" + ")
+		}
+		buf.WriteString(s)
+	}
+	return buf.String()
+}
+
+func (this Printer) Term(term ITerm) string {
+	coeff := this.number(term.C())
+	if ct, isComplex := term.(IComplexTerm); isComplex {
+		coeff = this.complexNumber(ct.CC())
+	}
+
+	vs := term.Vars()
+	if 0 == len(vs) {
+		return coeff
+	}
+
+	factors := make([]string, 0, len(vs))
+	for _, v := range vs {
+		factors = append(factors, this.Var(v))
+	}
+	if this.Grouped {
+		return coeff + "(" + strings.Join(factors, "*") + ")"
+	}
+
+	sep := "*"
+	if this.ImplicitMul {
+		sep = " "
+	}
+	product := strings.Join(factors, sep)
+	switch coeff {
+	case "1":
+		return product
+	case "-1":
+		return "-" + product
+	}
+	if !this.ImplicitMul || isDigit(product[0]) || readsAsExponent(product) {
+		return coeff + "*" + product
+	}
+	return coeff + product
+}
+
+// readsAsExponent reports whether s written after a number would be read
+// as its exponent, as "e1" in "3e1".
+func readsAsExponent(s string) bool {
+	if 2 > len(s) || ('e' != s[0] && 'E' != s[0]) {
+		return false
+	}
+	if '+' == s[1] || '-' == s[1] {
+		return 2 < len(s) && isDigit(s[2])
+	}
+	return isDigit(s[1])
+}
+
+func (this Printer) Var(v IVariable) string {
+	name := v.Name()
+	if fn, isFunc := v.(IFunction); isFunc {
+		args := this
+		if this.Grouped {
+			// function arguments are written as in their names
+			args = Printer{Digits: this.Digits}
+		}
+		strs := make([]string, 0, len(fn.Args()))
+		for _, arg := range fn.Args() {
+			strs = append(strs, args.Expr(arg))
+		}
+		name = fn.Kind().String() + "(" + strings.Join(strs, ", ") + ")"
+	}
+
+	if math0.IsApproxEqual(v.Power(), 1.0) {
+		return name
+	}
+	power := this.number(v.Power())
+	if this.Unicode {
+		if sup, ok := superscript(power); ok {
+			return name + sup
+		}
+	}
+	return name + "^" + power
+}
+
+func (this Printer) Equation(eqn IEquation) string {
+	return this.Expr(eqn.Left()) + " " + eqn.Relation().String() + " " + this.Expr(eqn.Right())
+}
+
+func (this Printer) number(f float64) string {
+	digits := this.Digits
+	if 0 >= digits {
+		digits = -1
+	}
+	return strconv.FormatFloat(f, 'g', digits, 64)
+}
+
+func (this Printer) complexNumber(c complex128) string {
+	if !isComplexCoeff(c) {
+		return this.number(real(c))
+	}
+	im := this.number(imag(c))
+	if !strings.HasPrefix(im, "-") {
+		im = "+" + im
+	}
+	return "(" + this.number(real(c)) + im + "i)"
+}
+
+func superscript(s string) (string, bool) {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		sup, ok := g_superscripts[r]
+		if !ok {
+			return "", false
+		}
+		out = append(out, sup)
+	}
+	return string(out), true
+}
+
+// format implements fmt.Formatter for the types below. %.3v prints with 3
+// significant digits and %+v folds signs, e.g. "x - 4*y"; other verbs and
+// plain %v print String() as before.
+func format(f fmt.State, verb rune, s string, pretty func(p Printer) string) {
+	prec, hasPrec := f.Precision()
+	if 'v' != verb || !(hasPrec || f.Flag('+')) {
+		fmt.Fprintf(f, fmt.FormatString(f, verb), s)
+		return
+	}
+
+	p := Printer{Grouped: !f.Flag('+'), FoldSigns: f.Flag('+')}
+	if hasPrec {
+		p.Digits = prec
+		if 0 == prec {
+			p.Digits = 1
+		}
+	}
+	fmt.Fprint(f, pretty(p))
+}
+
+func (this _Expression) Format(f fmt.State, verb rune) {
+	format(f, verb, this.String(), func(p Printer) string { return p.Expr(&this) })
+}
+
+func (this _Term) Format(f fmt.State, verb rune) {
+	format(f, verb, this.String(), func(p Printer) string { return p.Term(&this) })
+}
+
+func (this _ComplexTerm) Format(f fmt.State, verb rune) {
+	format(f, verb, this.String(), func(p Printer) string { return p.Term(&this) })
+}
+
+func (this _Variable) Format(f fmt.State, verb rune) {
+	format(f, verb, this.String(), func(p Printer) string { return p.Var(&this) })
+}
+
+func (this _Function) Format(f fmt.State, verb rune) {
+	format(f, verb, this.String(), func(p Printer) string { return p.Var(&this) })
+}
+
+func (this _Equation) Format(f fmt.State, verb rune) {
+	format(f, verb, this.String(), func(p Printer) string { return p.Equation(&this) })
+}
@@ -0,0 +1,77 @@
+package expr
+
+import (
+	"fmt"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+type _printertest struct {
+	p        Printer
+	expected string
+}
+
+var ttPrinter = []_printertest{
+	//0
+	_printertest{Printer{}, "-2.5e-07 + x*y^-1 + 3.14159265*x^2 + -4*y"},
+	//1
+	_printertest{Printer{Digits: 3}, "-2.5e-07 + x*y^-1 + 3.14*x^2 + -4*y"},
+	//2
+	_printertest{Printer{FoldSigns: true, ImplicitMul: true}, "-2.5e-07 + x y^-1 + 3.14159265x^2 - 4y"},
+	//3
+	_printertest{Printer{Unicode: true, ImplicitMul: true, Digits: 3}, "-2.5e-07 + x y⁻¹ + 3.14x² + -4y"},
+	//4
+	_printertest{Printer{Order: OrderByDegreeDesc, FoldSigns: true, Digits: 2}, "3.1*x^2 - 4*y - 2.5e-07 + x*y^-1"},
+	//5
+	_printertest{Printer{Order: OrderByDegreeAsc}, "-2.5e-07 + x*y^-1 + -4*y + 3.14159265*x^2"},
+	//6
+	_printertest{Printer{Grouped: true, Digits: 3}, "-2.5e-07 + 1(x*y^-1) + 3.14(x^2) + -4(y)"},
+}
+
+func TestPrinter(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// String() would print the constant as "-0"
+	e := mustParse("3.14159265x^2 - 4y + x/y - 0.00000025")
+	for i, tt := range ttPrinter {
+		assert.Equal(tt.expected, tt.p.Expr(e), "i=%d", i)
+	}
+
+	// the printed form parses back, with no variable read as an exponent
+	for _, e := range []IExpression{e, mustParse("3*e1 - 2*E+5*x + 0.5*e")} {
+		for _, p := range []Printer{Printer{}, Printer{FoldSigns: true, ImplicitMul: true}} {
+			back, err := ParseExpr(p.Expr(e))
+			if assert.Nil(err, p.Expr(e)) {
+				assert.Equal(e.String(), back.String(), p.Expr(e))
+			}
+		}
+	}
+	assert.Equal("0.5e + 3*e1", Printer{ImplicitMul: true}.Expr(mustParse("3*e1 + 0.5*e")))
+
+	p := Printer{FoldSigns: true, ImplicitMul: true, Unicode: true}
+	assert.Equal("-2 + 2sin(x y)²", p.Expr(mustParse("2sin(x*y)^2 - 2")))
+	assert.Equal("-1 + x^0.5 <= 2y", p.Equation(mustParseEquation("sqrt(1)*x^0.5 - 1 <= 2y")))
+	assert.Equal("0", p.Expr(mustParse("x - x")))
+	assert.Equal("(1-2i)s - s²", p.Expr(NewExpr(NewComplexTerm(1-2i, MustVars("s")...), NewTerm(-1, MustVars("s^2")...))))
+}
+
+func TestPrinter_Format(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e := mustParse("3.14159265x^2 - 4y")
+	assert.Equal(e.String(), fmt.Sprintf("%v", e))
+	assert.Equal(e.String(), fmt.Sprintf("%s", e))
+	assert.Equal("[3.141593(x^2) + -4(y)]", fmt.Sprintf("[%v]", e))
+	assert.Equal("3.14(x^2) + -4(y)", fmt.Sprintf("%.3v", e))
+	assert.Equal("3.14159265*x^2 - 4*y", fmt.Sprintf("%+v", e))
+	assert.Equal("3.1*x^2 - 4*y", fmt.Sprintf("%+.2v", e))
+	assert.Equal("3.1", fmt.Sprintf("%.3s", e), "%s keeps string precision")
+	assert.Equal(fmt.Sprintf("%30s", e.String()), fmt.Sprintf("%30v", e))
+
+	assert.Equal("3.14(x^2)", fmt.Sprintf("%.3v", e.TermAt(0)))
+	assert.Equal("x^0.333", fmt.Sprintf("%.3v", NewVarN("x", 1.0/3)))
+	assert.Equal("sin(0.333*x)^2", fmt.Sprintf("%.3v", mustParse("sin(x/3)^2").TermAt(0).VarAt(0)))
+	assert.Equal("3.14(x^2) + -4(y) <= 0.333", fmt.Sprintf("%.3v", Equation(e, LEQ, mustParse("1/3"))))
+	assert.Equal("(1+2i)(s)", fmt.Sprintf("%.3v", NewComplexTerm(1+2i, MustVars("s")...)))
+}
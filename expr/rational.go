@@ -0,0 +1,451 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// IRatTerm is an ITerm with an exact coefficient. Coefficients are compared
+// exactly, so 1/10 + 2/10 - 3/10 cancels to nothing.
+type IRatTerm interface {
+	fmt.Stringer
+	C() *big.Rat // a copy
+	SetC(c *big.Rat)
+
+	Vars() VariableList
+	SetVars(vs ...IVariable)
+
+	Clone() IRatTerm
+	Key() string
+}
+
+type RatTermList []IRatTerm
+
+// IRatExpression is a sum of IRatTerm, kept sorted by key with equal keys
+// combined and zero terms dropped.
+type IRatExpression interface {
+	fmt.Stringer
+	EachTerm(func(IRatTerm) bool)
+	Constant() *big.Rat
+	Terms() RatTermList
+	AddTerm(...IRatTerm)  // appends
+	SetTerms(...IRatTerm) // clears, then sets
+	Key() string
+	Clone() IRatExpression
+}
+
+// RatValuation assigns exact values to variables.
+type RatValuation map[string]*big.Rat
+
+type _RatTerm struct {
+	c    big.Rat
+	vars VariableList
+	key  *string
+}
+
+type _RatExpression struct {
+	terms RatTermList
+}
+
+func NewRatTerm(c *big.Rat, vs ...IVariable) IRatTerm {
+	o := &_RatTerm{}
+	o.c.Set(c)
+	o.SetVars(vs...)
+	return o
+}
+
+func NewRatExpr(terms ...IRatTerm) IRatExpression {
+	o := &_RatExpression{terms: terms}
+	if out, bWasModified := SimplifyRatExpression(o); bWasModified {
+		o.terms = out
+	}
+	return o
+}
+
+// RatOf returns the simplest fraction that rounds to f, so RatOf(0.1) is
+// 1/10 and RatOf(1.0/3) is 1/3 rather than the binary values of the floats.
+// It returns nil for NaN and infinity.
+func RatOf(f float64) *big.Rat {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	if 0 > f {
+		r := RatOf(-f)
+		return r.Neg(r)
+	}
+	exact := new(big.Rat).SetFloat64(f)
+	if f == math.Trunc(f) {
+		return exact
+	}
+
+	// every number strictly between the midpoints to the neighbouring
+	// floats rounds to f
+	lo := midpoint(exact, math.Nextafter(f, 0))
+	hi := midpoint(exact, math.Nextafter(f, math.Inf(1)))
+	return simplestBetween(lo, hi)
+}
+
+func midpoint(r *big.Rat, f float64) *big.Rat {
+	m := new(big.Rat).SetFloat64(f)
+	m.Add(m, r)
+	return m.Mul(m, big.NewRat(1, 2))
+}
+
+// simplestBetween returns the fraction with the smallest denominator in the
+// open interval (lo, hi), 0 <= lo < hi, by continued fractions. A nil hi
+// stands for infinity.
+func simplestBetween(lo, hi *big.Rat) *big.Rat {
+	a := new(big.Int).Quo(lo.Num(), lo.Denom()) // floor, lo is not negative
+	next := new(big.Rat).SetInt(a)
+	next.Add(next, big.NewRat(1, 1))
+	if nil == hi || 0 > next.Cmp(hi) {
+		return next
+	}
+
+	// (lo, hi) lies within [a, a+1], recurse on the reciprocals of the
+	// fractional parts
+	ra := new(big.Rat).SetInt(a)
+	fracHi := new(big.Rat).Sub(hi, ra)
+	fracLo := new(big.Rat).Sub(lo, ra)
+	var upper *big.Rat
+	if 0 != fracLo.Sign() {
+		upper = fracLo.Inv(fracLo)
+	}
+	r := simplestBetween(fracHi.Inv(fracHi), upper)
+	r.Inv(r)
+	return r.Add(r, ra)
+}
+
+// ToRatExpr converts the coefficients of e with RatOf. A coefficient that
+// is complex, NaN or infinite is an ErrDomain.
+func ToRatExpr(e IExpression) (IRatExpression, error) {
+	if err := CheckReal(e); nil != err {
+		return nil, err
+	}
+
+	var terms RatTermList
+	var err error
+	e.EachTerm(func(term ITerm) bool {
+		c := RatOf(term.C())
+		if nil == c {
+			err = ErrDomain(fmt.Errorf("%s has no exact coefficient", term))
+			return false
+		}
+		terms = append(terms, NewRatTerm(c, term.Vars()...))
+		return true
+	})
+	if nil != err {
+		return nil, err
+	}
+	return NewRatExpr(terms...), nil
+}
+
+// ToFloatExpr converts e to the float form, built with the EqnBuilder_*
+// constructors. Coefficients are rounded to the nearest float64.
+func ToFloatExpr(e IRatExpression) IExpression {
+	var terms TermList
+	e.EachTerm(func(term IRatTerm) bool {
+		c, _ := term.C().Float64()
+		vs := make(VariableList, 0, len(term.Vars()))
+		for _, v := range term.Vars() {
+			if _, isFunc := v.(IFunction); isFunc {
+				vs = append(vs, v)
+			} else {
+				vs = append(vs, EqnBuilder_VarConstructor(v.Name(), v.Power()))
+			}
+		}
+		terms = append(terms, EqnBuilder_TermConstructor(c, vs...))
+		return true
+	})
+	return newExprOf(terms...)
+}
+
+// ValueOfRatExpr evaluates e exactly. Powers must be integral and function
+// factors have no exact value, both are reported as ErrDomain.
+func ValueOfRatExpr(e IRatExpression, m RatValuation) (value *big.Rat, err error) {
+	value = new(big.Rat)
+	e.EachTerm(func(term IRatTerm) bool {
+		termValue := term.C()
+		for _, v := range term.Vars() {
+			var f *big.Rat
+			if f, err = ratPowOf(v, m); nil != err {
+				return false
+			}
+			termValue.Mul(termValue, f)
+		}
+		value.Add(value, termValue)
+		return true
+	})
+	if nil != err {
+		return nil, err
+	}
+	return
+}
+
+func ratPowOf(v IVariable, m RatValuation) (*big.Rat, error) {
+	if _, isFunc := v.(IFunction); isFunc {
+		return nil, ErrDomain(fmt.Errorf("%s has no exact value", v))
+	}
+	if !isIntegral(v.Power()) {
+		return nil, ErrDomain(fmt.Errorf("%s has no exact value", v))
+	}
+	c, has := m[v.Name()]
+	if !has || nil == c {
+		return nil, ErrNoValuationForVar(fmt.Errorf("var=%s has no valuation", v.Name()))
+	}
+
+	n := int(math.Round(v.Power()))
+	base := new(big.Rat).Set(c)
+	if 0 > n {
+		if 0 == base.Sign() {
+			return nil, ErrDivisionByZero(fmt.Errorf("%s is undefined at %s=0", v, v.Name()))
+		}
+		base.Inv(base)
+		n = -n
+	}
+
+	out := big.NewRat(1, 1)
+	for ; 0 < n; n >>= 1 {
+		if 1 == n&1 {
+			out.Mul(out, base)
+		}
+		if 1 < n {
+			base.Mul(base, base)
+		}
+	}
+	return out, nil
+}
+
+// SimplifyRatExpression sorts the terms of e by key, adds the coefficients
+// of equal keys and drops the terms that are exactly zero.
+func SimplifyRatExpression(e IRatExpression) (out RatTermList, bDidSomething bool) {
+	terms := e.Terms()
+	if terms.IsSimplified() {
+		return
+	}
+	if !terms.IsSorted() {
+		sort.SliceStable(terms, terms.Less)
+	}
+	bDidSomething = true
+
+	out = make(RatTermList, 0, len(terms))
+	for _, term := range terms {
+		c := term.C()
+		if 0 == c.Sign() {
+			continue
+		}
+
+		n := len(out)
+		if 0 < n && term.Key() == out[n-1].Key() {
+			c.Add(c, out[n-1].C())
+			if 0 == c.Sign() {
+				out = out[:n-1]
+			} else {
+				out[n-1].SetC(c)
+			}
+		} else {
+			out = append(out, term)
+		}
+	}
+
+	return
+}
+
+// RatAdd returns a + b. The operands are not modified.
+func RatAdd(a, b IRatExpression) IRatExpression {
+	return NewRatExpr(append(a.Clone().Terms(), b.Clone().Terms()...)...)
+}
+
+// RatSub returns a - b.
+func RatSub(a, b IRatExpression) IRatExpression {
+	return RatAdd(a, RatNeg(b))
+}
+
+// RatNeg returns -e.
+func RatNeg(e IRatExpression) IRatExpression {
+	terms := e.Clone().Terms()
+	for _, term := range terms {
+		term.SetC(new(big.Rat).Neg(term.C()))
+	}
+	return NewRatExpr(terms...)
+}
+
+// RatMul returns the expanded product a * b.
+func RatMul(a, b IRatExpression) IRatExpression {
+	var terms RatTermList
+	a.EachTerm(func(ta IRatTerm) bool {
+		b.EachTerm(func(tb IRatTerm) bool {
+			c := ta.C()
+			terms = append(terms, NewRatTerm(c.Mul(c, tb.C()), mulVars(ta.Vars(), tb.Vars())...))
+			return true
+		})
+		return true
+	})
+	return NewRatExpr(terms...)
+}
+
+// RatPow returns the expanded e^n. A negative n is only supported when e is
+// a single term: a zero e is an ErrDivisionByZero and several terms are an
+// ErrNotPolynomial, as for Pow.
+func RatPow(e IRatExpression, n int) (IRatExpression, error) {
+	if 0 > n {
+		terms := e.Terms()
+		if 1 < len(terms) {
+			return nil, ErrNotPolynomial(fmt.Errorf("(%s)^%d is not a sum of terms", e, n))
+		}
+		if 0 == len(terms) || 0 == terms[0].C().Sign() {
+			return nil, ErrDivisionByZero(fmt.Errorf("(%s)^%d is a division by zero", e, n))
+		}
+		vs := make(VariableList, 0, len(terms[0].Vars()))
+		for _, v := range terms[0].Vars() {
+			vs = append(vs, withPower(v, -v.Power()))
+		}
+		c := terms[0].C()
+		e, n = NewRatExpr(NewRatTerm(c.Inv(c), vs...)), -n
+	}
+
+	out := NewRatExpr(NewRatTerm(big.NewRat(1, 1)))
+	for base := e; 0 < n; n >>= 1 {
+		if 1 == n&1 {
+			out = RatMul(out, base)
+		}
+		if 1 < n {
+			base = RatMul(base, base)
+		}
+	}
+	return out, nil
+}
+
+func (this *_RatTerm) C() *big.Rat {
+	return new(big.Rat).Set(&this.c)
+}
+
+func (this *_RatTerm) SetC(c *big.Rat) {
+	this.c.Set(c)
+}
+
+func (this *_RatTerm) Vars() VariableList {
+	return this.vars
+}
+
+// SetVars sorts vs by name and merges the powers of repeated variables.
+func (this *_RatTerm) SetVars(vs ...IVariable) {
+	this.key = nil
+	this.vars = mulVars(vs, nil)
+}
+
+func (this *_RatTerm) Key() string {
+	if nil == this.key {
+		k := this.vars.Key()
+		this.key = &k
+	}
+	return *this.key
+}
+
+func (this *_RatTerm) Clone() IRatTerm {
+	return NewRatTerm(&this.c, this.vars...)
+}
+
+// String is like the float form, e.g. "-3/4(x^2*y)".
+func (this *_RatTerm) String() string {
+	if 0 == len(this.vars) {
+		return this.c.RatString()
+	}
+	return fmt.Sprintf("%s(%s)", this.c.RatString(), this.vars.String())
+}
+
+func (this RatTermList) Less(i, j int) bool {
+	return this[i].Key() < this[j].Key()
+}
+
+func (this RatTermList) IsSorted() bool {
+	if 1 >= len(this) {
+		return true
+	}
+	return sort.SliceIsSorted(this, this.Less)
+}
+
+// IsSimplified reports whether the terms are strictly sorted and nonzero.
+func (this RatTermList) IsSimplified() bool {
+	for i, term := range this {
+		if 0 == term.C().Sign() {
+			return false
+		}
+		if 0 < i && !this.Less(i-1, i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (this RatTermList) String() string {
+	if 0 == len(this) {
+		return "0"
+	}
+
+	buf := bytes.NewBufferString(this[0].String())
+	for _, term := range this[1:] {
+		buf.WriteString(" + ")
+		buf.WriteString(term.String())
+	}
+	return buf.String()
+}
+
+func (this _RatExpression) EachTerm(cb func(term IRatTerm) bool) {
+	for _, term := range this.terms {
+		if !cb(term) {
+			return
+		}
+	}
+}
+
+func (this _RatExpression) Constant() *big.Rat {
+	if 0 == len(this.terms) || 0 < len(this.terms[0].Vars()) {
+		return new(big.Rat)
+	}
+	return this.terms[0].C()
+}
+
+func (this _RatExpression) Terms() RatTermList {
+	return this.terms
+}
+
+func (this *_RatExpression) AddTerm(terms ...IRatTerm) {
+	this.terms = append(this.terms, terms...)
+	if out, bWasModified := SimplifyRatExpression(this); bWasModified {
+		this.terms = out
+	}
+}
+
+func (this *_RatExpression) SetTerms(terms ...IRatTerm) {
+	this.terms = nil
+	this.AddTerm(terms...)
+}
+
+func (this _RatExpression) Key() string {
+	buf := bytes.NewBufferString("")
+	for _, term := range this.terms {
+		if k := term.Key(); 0 < len(k) {
+			if 0 < buf.Len() {
+				buf.WriteString(",")
+			}
+			buf.WriteString(k)
+		}
+	}
+	return buf.String()
+}
+
+func (this _RatExpression) String() string {
+	return this.terms.String()
+}
+
+func (this _RatExpression) Clone() IRatExpression {
+	terms := make(RatTermList, len(this.terms))
+	for i, term := range this.terms {
+		terms[i] = term.Clone()
+	}
+	return &_RatExpression{terms: terms}
+}
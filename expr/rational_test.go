@@ -0,0 +1,139 @@
+package expr
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestRatExpression(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// 0.1x + 0.2x - 0.3x leaves a tiny float term but cancels exactly
+	x := MustVars("x")
+	e := NewRatExpr(
+		NewRatTerm(RatOf(0.1), x...),
+		NewRatTerm(RatOf(0.2), x...),
+		NewRatTerm(RatOf(-0.3), x...),
+		NewRatTerm(big.NewRat(1, 3)),
+	)
+	assert.Equal("1/3", e.String())
+	assert.Equal(0, big.NewRat(1, 3).Cmp(e.Constant()))
+
+	// coefficients far below the float tolerance are kept
+	tiny := NewRatExpr(NewRatTerm(big.NewRat(1, 1000000000000), x...))
+	assert.Equal("1/1000000000000(x)", tiny.String())
+	assert.Equal("1/1000000000000(x)", RatAdd(tiny, NewRatExpr()).String())
+
+	a := mustRat(mustParse("x/2 + 1/3"))
+	b := mustRat(mustParse("x - 1"))
+	assert.Equal("-1 + 1(x)", b.String())
+	half := NewRatExpr(NewRatTerm(big.NewRat(1, 2)))
+	assert.Equal("-1/2 + 1(x)", RatSub(b, RatNeg(half)).String())
+	assert.Equal("1", mustRatPow(b, 0).String())
+	assert.Equal("-1/3 + -1/6(x) + 1/2(x^2)", RatMul(a, b).String())
+	assert.Equal("1 + -2(x) + 1(x^2)", mustRatPow(b, 2).String())
+	assert.Equal("4(x^-2)", mustRatPow(NewRatExpr(NewRatTerm(big.NewRat(1, 2), x...)), -2).String())
+	_, err := RatPow(b, -1)
+	assert.NotNil(err)
+	_, err = RatPow(NewRatExpr(), -1)
+	assert.NotNil(err)
+	assert.Equal("-1 + 1(x)", b.String(), "operands are not modified")
+
+	inv := mustRatPow(mustRat(mustParse("2sin(x)^2*y")), -1)
+	assert.Equal("1/2(sin(x)^-2*y^-1)", inv.String())
+	_, isFunc := inv.Terms()[0].Vars()[0].(IFunction)
+	assert.True(isFunc)
+}
+
+func TestToRatExpr(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	r, err := ToRatExpr(mustParse("0.1x + 1/3"))
+	if assert.Nil(err) {
+		assert.Equal("1/3 + 1/10(x)", r.String())
+	}
+	for _, c := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err = ToRatExpr(NewExpr(NewTerm(1), NewTerm(c, NewVar("x"))))
+		assert.NotNil(err, "%v", c)
+	}
+}
+
+func mustRat(e IExpression) IRatExpression {
+	r, err := ToRatExpr(e)
+	if nil != err {
+		panic(err)
+	}
+	return r
+}
+
+func mustRatPow(e IRatExpression, n int) IRatExpression {
+	out, err := RatPow(e, n)
+	if nil != err {
+		panic(err)
+	}
+	return out
+}
+
+func TestValueOfRatExpr(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e := mustRat(mustParse("x^2/3 - y^-1 + 0.1"))
+	v, err := ValueOfRatExpr(e, RatValuation{"x": big.NewRat(1, 2), "y": big.NewRat(3, 1)})
+	if assert.Nil(err) {
+		// 1/12 - 1/3 + 1/10
+		assert.Equal("-3/20", v.RatString())
+	}
+
+	_, err = ValueOfRatExpr(e, RatValuation{"x": big.NewRat(1, 2), "y": new(big.Rat)})
+	assert.NotNil(err)
+	_, err = ValueOfRatExpr(e, RatValuation{"x": big.NewRat(1, 2)})
+	assert.NotNil(err)
+	_, err = ValueOfRatExpr(mustRat(mustParse("x^0.5")), RatValuation{"x": big.NewRat(4, 1)})
+	assert.NotNil(err)
+	_, err = ValueOfRatExpr(mustRat(mustParse("sin(x)")), RatValuation{"x": big.NewRat(0, 1)})
+	assert.NotNil(err)
+}
+
+func TestToFloatExpr(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for _, s := range []string{"3x^2 - 2x*y + 5", "0.1x + 0.25", "0", "2sin(x)^2 + x^-1"} {
+		e := mustParse(s)
+		r := mustRat(e)
+		assert.Equal(e.String(), ToFloatExpr(r).String(), s)
+		assert.Equal(e.Key(), r.Key(), s)
+	}
+
+	r := NewRatExpr(NewRatTerm(big.NewRat(1, 3), MustVars("x")...))
+	v, err := ValueOfExpr(ToFloatExpr(r), Valuation{"x": 3})
+	assert.Nil(err)
+	assert.InDelta(1.0, v, 1e-15)
+}
+
+func TestRatOf(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	tenth, fifth := 0.1, 0.2
+	for f, expected := range map[float64]string{
+		0.1:      "1/10",
+		1.0 / 3:  "1/3",
+		-2.0 / 7: "-2/7",
+		0:        "0",
+		5:        "5",
+		1e-12:    "1/1000000000000",
+		1e20:     "100000000000000000000",
+	} {
+		assert.Equal(expected, RatOf(f).RatString(), "f=%v", f)
+	}
+	assert.Nil(RatOf(math.NaN()))
+	assert.NotEqual("3/10", RatOf(tenth+fifth).RatString(), "0.1+0.2 is not the float 0.3")
+
+	// converting back gives the same float
+	for _, f := range []float64{math.Pi, math.SmallestNonzeroFloat64, math.MaxFloat64, 123.456e-7} {
+		back, _ := RatOf(f).Float64()
+		assert.Equal(f, back, "f=%v", f)
+	}
+}
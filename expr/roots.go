@@ -0,0 +1,236 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"sort"
+
+	"github.com/noypi/math0"
+)
+
+const (
+	rootsMaxIterations = 500
+	rootsRoundoff      = 2e-15 // bounds the relative error of evaluating p(z)
+)
+
+// Roots returns the complex roots of e, a polynomial in varname alone,
+// repeated by multiplicity and sorted by real then imaginary part. A root
+// of multiplicity k is only found to about the k-th root of the precision,
+// so the roots are found once in each part of the square-free decomposition
+// of e and repeated. Degrees up to 2 use closed forms, higher degrees use
+// Aberth's method, which is an ErrNotConverged if it does not converge.
+// Complex coefficients are an ErrDomain.
+func Roots(e IExpression, varname string) ([]complex128, error) {
+	coeffs, err := univariateCoeffs(e, varname)
+	if nil != err {
+		return nil, err
+	}
+	if 0 == len(coeffs) {
+		return nil, ErrZeroPolynomial(fmt.Errorf("every %s is a root of 0", varname))
+	}
+
+	var roots []complex128
+	for 0.0 == coeffs[0] && 1 < len(coeffs) {
+		roots = append(roots, 0)
+		coeffs = coeffs[1:]
+	}
+
+	parts, err := squareFreeParts(coeffs)
+	if nil != err {
+		return nil, err
+	}
+	for i, part := range parts {
+		var zs []complex128
+		switch len(part) - 1 {
+		case 0:
+		case 1:
+			zs = []complex128{complex(-part[0]/part[1], 0)}
+		case 2:
+			zs = quadraticRoots(part[2], part[1], part[0])
+		default:
+			if zs, err = aberthRoots(part); nil != err {
+				return nil, err
+			}
+		}
+		for k := 0; k <= i; k++ {
+			roots = append(roots, zs...)
+		}
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		if real(roots[i]) != real(roots[j]) {
+			return real(roots[i]) < real(roots[j])
+		}
+		return imag(roots[i]) < imag(roots[j])
+	})
+	return roots, nil
+}
+
+// RealRoots returns the real roots of e in ascending order, repeated by
+// multiplicity.
+func RealRoots(e IExpression, varname string) ([]float64, error) {
+	roots, err := Roots(e, varname)
+	if nil != err {
+		return nil, err
+	}
+
+	var out []float64
+	for _, z := range roots {
+		if math.Abs(imag(z)) <= 1e-7*math.Max(1, cmplx.Abs(z)) {
+			out = append(out, real(z))
+		}
+	}
+	sort.Float64s(out)
+	return out, nil
+}
+
+// univariateCoeffs returns the coefficients of e indexed by the power of
+// varname, without trailing zeros.
+func univariateCoeffs(e IExpression, varname string) (coeffs []float64, err error) {
+	if err = checkPolynomial(e); nil != err {
+		return
+	}
+	if err = CheckReal(e); nil != err {
+		return
+	}
+
+	e.EachTerm(func(term ITerm) bool {
+		n := 0
+		for _, v := range term.Vars() {
+			if v.Name() != varname {
+				err = ErrNotPolynomial(fmt.Errorf("%s is not a polynomial in %s alone", e, varname))
+				return false
+			}
+			n = int(math.Round(v.Power()))
+		}
+		for len(coeffs) <= n {
+			coeffs = append(coeffs, 0)
+		}
+		coeffs[n] += term.C()
+		return true
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	for 0 < len(coeffs) && math0.IsApproxEqual(coeffs[len(coeffs)-1], 0.0) {
+		coeffs = coeffs[:len(coeffs)-1]
+	}
+	return
+}
+
+// squareFreeParts is Yun's decomposition of coeffs, exact over the
+// rationals: the polynomial is a constant times the product of parts[i] to
+// the power i+1, each part without multiple roots.
+func squareFreeParts(coeffs []float64) ([][]float64, error) {
+	rats := make([]*big.Rat, len(coeffs))
+	for i, c := range coeffs {
+		if rats[i] = RatOf(c); nil == rats[i] {
+			return nil, ErrDomain(fmt.Errorf("roots: the coefficient %v is not finite", c))
+		}
+	}
+	_, f := primitiveOf(rats)
+	if 0 == f.degree() {
+		return nil, nil
+	}
+
+	var parts [][]float64
+	for _, part := range squareFree(f) {
+		fs := make([]float64, len(part))
+		for i, c := range part {
+			fs[i], _ = new(big.Float).SetInt(c).Float64()
+		}
+		parts = append(parts, fs)
+	}
+	return parts, nil
+}
+
+func quadraticRoots(a, b, c float64) []complex128 {
+	// q avoids cancelling b against the square root
+	s := cmplx.Sqrt(complex(b*b-4*a*c, 0))
+	if 0 > b {
+		s = -s
+	}
+	q := -0.5 * (complex(b, 0) + s)
+	if 0 == q {
+		return []complex128{0, 0}
+	}
+	return []complex128{q / complex(a, 0), complex(c, 0) / q}
+}
+
+func evalPoly(coeffs []float64, z complex128) (p, dp complex128) {
+	for i := len(coeffs) - 1; 0 <= i; i-- {
+		dp = dp*z + p
+		p = p*z + complex(coeffs[i], 0)
+	}
+	return
+}
+
+// evalAbs is the sum of |c_i| r^i.
+func evalAbs(coeffs []float64, r float64) (sum float64) {
+	for i := len(coeffs) - 1; 0 <= i; i-- {
+		sum = sum*r + math.Abs(coeffs[i])
+	}
+	return
+}
+
+func aberthRoots(coeffs []float64) ([]complex128, error) {
+	n := len(coeffs) - 1
+
+	// start on a circle within the Cauchy bound
+	radius := 0.0
+	for _, c := range coeffs[:n] {
+		radius = math.Max(radius, math.Abs(c/coeffs[n]))
+	}
+	radius = math.Min(1+radius, 1+math.Pow(radius, 1/float64(n)))
+	center := -coeffs[n-1] / (float64(n) * coeffs[n])
+
+	z := make([]complex128, n)
+	for k := range z {
+		angle := 2*math.Pi*float64(k)/float64(n) + 0.4
+		z[k] = complex(center, 0) + cmplx.Rect(radius, angle)
+	}
+
+	bConverged := false
+	for iter := 0; iter < rootsMaxIterations && !bConverged; iter++ {
+		bConverged = true
+		for k := range z {
+			// stop at p(z) within the rounding error of evaluating it
+			p, dp := evalPoly(coeffs, z[k])
+			if cmplx.Abs(p) <= rootsRoundoff*evalAbs(coeffs, cmplx.Abs(z[k])) {
+				continue
+			}
+			ratio := p / dp
+			sum := complex(0, 0)
+			for j := range z {
+				if j != k {
+					sum += 1 / (z[k] - z[j])
+				}
+			}
+			w := ratio / (1 - ratio*sum)
+			if cmplx.IsNaN(w) || cmplx.IsInf(w) {
+				continue
+			}
+			z[k] -= w
+			if cmplx.Abs(w) > 1e-14*(1+cmplx.Abs(z[k])) {
+				bConverged = false
+			}
+		}
+	}
+	if !bConverged {
+		return nil, ErrNotConverged(fmt.Errorf("roots: Aberth's method did not converge in %d iterations", rootsMaxIterations))
+	}
+
+	// drop the imaginary noise of real roots, and the real noise of
+	// imaginary ones
+	for k := range z {
+		if math.Abs(imag(z[k])) < 1e-12*(1+math.Abs(real(z[k]))) {
+			z[k] = complex(real(z[k]), 0)
+		} else if math.Abs(real(z[k])) < 1e-12*(1+math.Abs(imag(z[k]))) {
+			z[k] = complex(0, imag(z[k]))
+		}
+	}
+	return z, nil
+}
@@ -0,0 +1,95 @@
+package expr
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+type _rootstest struct {
+	e        string
+	expected []float64
+}
+
+var ttRealRoots = []_rootstest{
+	//0
+	_rootstest{"2x - 4", []float64{2}},
+	//1
+	_rootstest{"x^2 - 3x + 2", []float64{1, 2}},
+	//2
+	_rootstest{"x^2 + 1", nil},
+	//3
+	_rootstest{"x^3 - 6x^2 + 11x - 6", []float64{1, 2, 3}},
+	//4
+	_rootstest{"x^5 - x", []float64{-1, 0, 1}},
+	//5
+	_rootstest{"(x - 1)^2*(x + 3)", []float64{-3, 1, 1}},
+	//6
+	_rootstest{"(x - 1)(x - 2)(x - 3)(x - 4)(x - 5)(x - 6)(x - 7)", []float64{1, 2, 3, 4, 5, 6, 7}},
+	//7
+	_rootstest{"5", nil},
+	//8
+	_rootstest{"(x - 1)^3", []float64{1, 1, 1}},
+	//9
+	_rootstest{"(x - 1)^4", []float64{1, 1, 1, 1}},
+	//10
+	_rootstest{"(x - 2)^3*(x + 1)^2*(x^2 + 1)", []float64{-1, -1, 2, 2, 2}},
+	//11
+	_rootstest{"x^2*(0.5x - 0.25)^4", []float64{0, 0, 0.5, 0.5, 0.5, 0.5}},
+}
+
+func TestRealRoots(t *testing.T) {
+	assert := assertpkg.New(t)
+	for i, tt := range ttRealRoots {
+		roots, err := RealRoots(mustParse(tt.e), "x")
+		if !assert.Nil(err, "i=%d", i) || !assert.Equal(len(tt.expected), len(roots), "i=%d %v", i, roots) {
+			continue
+		}
+		for j := range roots {
+			assert.InDelta(tt.expected[j], roots[j], 1e-6, "i=%d", i)
+		}
+	}
+}
+
+func TestRoots(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	roots, err := Roots(mustParse("x^4 - 1"), "x")
+	assert.Nil(err)
+	expected := []complex128{-1, complex(0, -1), complex(0, 1), 1}
+	if assert.Equal(len(expected), len(roots)) {
+		for i := range roots {
+			assert.True(cmplx.Abs(expected[i]-roots[i]) < 1e-9, "i=%d %v", i, roots)
+		}
+	}
+
+	// a quadruple pair of complex roots
+	roots, err = Roots(mustPow(mustParse("x^2 + 1"), 4), "x")
+	assert.Nil(err)
+	if assert.Equal(8, len(roots)) {
+		for i, z := range roots {
+			assert.True(cmplx.Abs(expected[1+i/4]-z) < 1e-9, "i=%d %v", i, roots)
+		}
+	}
+
+	roots, err = Roots(mustParse("x^2 + 2x + 5"), "x")
+	assert.Nil(err)
+	assert.Equal([]complex128{complex(-1, -2), complex(-1, 2)}, roots)
+
+	_, err = Roots(mustParse("x*y + 1"), "x")
+	assert.NotNil(err)
+	_, err = Roots(mustParse("x - x"), "x")
+	assert.NotNil(err)
+	_, err = Roots(mustParse("x^2.5"), "x")
+	assert.NotNil(err)
+
+	roots, err = Roots(mustParse("x^3 + 1e-3x + 1"), "x")
+	assert.Nil(err)
+	for _, z := range roots {
+		p, _ := evalPoly([]float64{1, 1e-3, 0, 1}, z)
+		assert.True(cmplx.Abs(p) < 1e-9, "%v", z)
+	}
+	assert.False(math.IsNaN(real(roots[0])))
+}
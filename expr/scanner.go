@@ -0,0 +1,201 @@
+package expr
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+type _TokenKind int
+
+const (
+	tokEOF _TokenKind = iota
+	tokNumber
+	tokIdent
+	tokPlus
+	tokMinus
+	tokStar
+	tokSlash
+	tokCaret
+	tokLParen
+	tokRParen
+	tokComma
+	tokRelation
+	tokInvalid
+)
+
+type _Token struct {
+	kind     _TokenKind
+	text     string
+	offset   int
+	relation Relation
+}
+
+type _Scanner struct {
+	src    string
+	offset int
+}
+
+func (this _TokenKind) String() string {
+	switch this {
+	case tokEOF:
+		return "end of input"
+	case tokNumber:
+		return "number"
+	case tokIdent:
+		return "identifier"
+	case tokPlus:
+		return "'+'"
+	case tokMinus:
+		return "'-'"
+	case tokStar:
+		return "'*'"
+	case tokSlash:
+		return "'/'"
+	case tokCaret:
+		return "'^'"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	case tokComma:
+		return "','"
+	case tokRelation:
+		return "relation"
+	}
+	return "invalid token"
+}
+
+func (this _Token) String() string {
+	if tokEOF == this.kind {
+		return this.kind.String()
+	}
+	return fmt.Sprintf("%q", this.text)
+}
+
+func isIdentStart(r rune) bool {
+	return '_' == r || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// Next returns the next token, skipping white space.
+func (this *_Scanner) Next() (tok _Token) {
+	for this.offset < len(this.src) {
+		r, n := utf8.DecodeRuneInString(this.src[this.offset:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		this.offset += n
+	}
+
+	tok.offset = this.offset
+	if len(this.src) <= this.offset {
+		tok.kind = tokEOF
+		return
+	}
+
+	start := this.offset
+	r, n := utf8.DecodeRuneInString(this.src[start:])
+	switch {
+	case (r < utf8.RuneSelf && isDigit(byte(r))) || ('.' == r && start+1 < len(this.src) && isDigit(this.src[start+1])):
+		this.scanNumber()
+		tok.kind = tokNumber
+
+	case isIdentStart(r):
+		this.offset += n
+		for this.offset < len(this.src) {
+			r, n = utf8.DecodeRuneInString(this.src[this.offset:])
+			if !isIdentPart(r) {
+				break
+			}
+			this.offset += n
+		}
+		tok.kind = tokIdent
+
+	default:
+		this.offset += n
+		tok.kind = tokInvalid
+		switch r {
+		case '+':
+			tok.kind = tokPlus
+		case '-':
+			tok.kind = tokMinus
+		case '*':
+			tok.kind = tokStar
+		case '/':
+			tok.kind = tokSlash
+		case '^':
+			tok.kind = tokCaret
+		case '(':
+			tok.kind = tokLParen
+		case ')':
+			tok.kind = tokRParen
+		case ',':
+			tok.kind = tokComma
+		case '<', '>', '=', '!':
+			tok.kind, tok.relation = this.scanRelation(r)
+		}
+	}
+
+	tok.text = this.src[start:this.offset]
+	return
+}
+
+func (this *_Scanner) scanNumber() {
+	src := this.src
+	for this.offset < len(src) && isDigit(src[this.offset]) {
+		this.offset++
+	}
+	if this.offset < len(src) && '.' == src[this.offset] {
+		this.offset++
+		for this.offset < len(src) && isDigit(src[this.offset]) {
+			this.offset++
+		}
+	}
+
+	// an exponent is only consumed when digits follow, so that "2e" reads as
+	// 2 times the variable e.
+	if this.offset < len(src) && ('e' == src[this.offset] || 'E' == src[this.offset]) {
+		i := this.offset + 1
+		if i < len(src) && ('+' == src[i] || '-' == src[i]) {
+			i++
+		}
+		if i < len(src) && isDigit(src[i]) {
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			this.offset = i
+		}
+	}
+}
+
+func (this *_Scanner) scanRelation(first rune) (_TokenKind, Relation) {
+	hasEq := this.offset < len(this.src) && '=' == this.src[this.offset]
+	if hasEq {
+		this.offset++
+	}
+
+	switch {
+	case '<' == first && hasEq:
+		return tokRelation, LEQ
+	case '<' == first:
+		return tokRelation, Lesser
+	case '>' == first && hasEq:
+		return tokRelation, GEQ
+	case '>' == first:
+		return tokRelation, Greater
+	case '!' == first && hasEq:
+		return tokRelation, NEQ
+	case '=' == first:
+		// both "=" and "==" are accepted
+		return tokRelation, EQ
+	}
+	return tokInvalid, 0
+}
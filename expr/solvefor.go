@@ -0,0 +1,63 @@
+package expr
+
+import (
+	"fmt"
+
+	"github.com/noypi/math0"
+)
+
+// SolveFor rearranges an equation linear in varname as varname <rel> e,
+// e.g. "3 - 2x <= y" as "x >= 1.5 + -0.5(y)". The coefficient of varname
+// may be symbolic only for EQ and NEQ, where it becomes a factor 1/a or
+// pow(a, -1) and is assumed nonzero. A coefficient that simplifies to zero
+// is an ErrDivisionByZero, a nonlinear occurrence an ErrNotLinear.
+func SolveFor(eqn IEquation, varname string) (IEquation, error) {
+	var a, b TermList
+	var err error
+	Sub(eqn.Left(), eqn.Right()).EachTerm(func(term ITerm) bool {
+		var rest VariableList
+		hasVar := false
+		for _, v := range term.Vars() {
+			switch {
+			case varname != v.Name() && !dependsOn(v, varname):
+				rest = append(rest, v)
+			case varname == v.Name() && !hasVar && math0.IsApproxEqual(v.Power(), 1.0):
+				hasVar = true
+			default:
+				err = ErrNotLinear(fmt.Errorf("%s is not linear in %s", eqn, varname))
+				return false
+			}
+		}
+		if hasVar {
+			a = append(a, newTermC(coeffOf(term), rest...))
+		} else {
+			b = append(b, term.Clone())
+		}
+		return true
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	coeff := newExprOf(a...)
+	if isZeroExpr(coeff) {
+		return nil, ErrDivisionByZero(fmt.Errorf("the coefficient of %s in %s is zero", varname, eqn))
+	}
+
+	// a*x + b <rel> 0, so x <rel> -b/a with rel flipped for a negative a
+	rel := eqn.Relation()
+	rhs := Neg(newExprOf(b...))
+	if c, isConst := ConstantOf(coeff); isConst {
+		if 0 > c {
+			rel = rel.Flip()
+		}
+		rhs = scaleExpr(rhs, 1/c)
+	} else if EQ != rel && NEQ != rel {
+		return nil, ErrDomain(fmt.Errorf("the sign of %s, the coefficient of %s, is unknown", coeff, varname))
+	} else {
+		rhs = Mul(rhs, reciprocal(coeff))
+	}
+
+	x := newExprOf(EqnBuilder_TermConstructor(1, EqnBuilder_VarConstructor(varname, 1)))
+	return Equation(x, rel, rhs), nil
+}
@@ -0,0 +1,40 @@
+package expr
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestSolveFor(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for _, tt := range []_parsetest{
+		_parsetest{"2x + 3 == 7", "1(x) == 2"},
+		_parsetest{"3 - 2x <= y", "1(x) >= 1.5 + -0.5(y)"},
+		_parsetest{"3 - 2x < y", "1(x) > 1.5 + -0.5(y)"},
+		_parsetest{"4x >= 2y + 8", "1(x) >= 2 + 0.5(y)"},
+		_parsetest{"-x > 1", "1(x) < -1"},
+		_parsetest{"x*y + 2 == z", "1(x) == -2(y^-1) + 1(y^-1*z)"},
+		_parsetest{"a*x + b*x != c", "1(x) != 1(c*pow(a + b, -1))"},
+		_parsetest{"x + sin(y) == x^2*0 + 2x", "1(x) == 1(sin(y))"},
+	} {
+		eqn, err := SolveFor(mustParseEquation(tt.s), "x")
+		if assert.Nil(err, tt.s) {
+			assert.Equal(tt.expected, eqn.String(), tt.s)
+		}
+	}
+
+	for _, s := range []string{
+		"x^2 == 4",
+		"x*x + x == 1",
+		"sin(x) == 0",
+		"x + pow(2, x) == 1",
+		"x + y == x",
+		"y == 1",
+		"a*x <= 1",
+	} {
+		_, err := SolveFor(mustParseEquation(s), "x")
+		assert.NotNil(err, s)
+	}
+}
@@ -0,0 +1,95 @@
+package expr
+
+// Substitute replaces every occurrence of varname in e, including inside
+// function arguments, with replacement. A power of replacement that cannot
+// be expanded, e.g. x^0.5 with x = t + 1, becomes pow(t + 1, 0.5).
+func Substitute(e IExpression, varname string, replacement IExpression) IExpression {
+	out := newExprOf()
+	e.EachTerm(func(term ITerm) bool {
+		replaced := newExprOf(NewTerm(1))
+		vs := make(VariableList, 0, len(term.Vars()))
+		for _, v := range term.Vars() {
+			if fn, isFunc := v.(IFunction); isFunc && dependsOn(v, varname) {
+				args := make([]IExpression, len(fn.Args()))
+				for i, arg := range fn.Args() {
+					args[i] = Substitute(arg, varname, replacement)
+				}
+				vs = append(vs, NewFuncN(fn.Kind(), fn.Power(), args...))
+			} else if !isFunc && v.Name() == varname {
+				var err error
+				if replaced, err = PowReal(replacement, v.Power()); nil != err {
+					replaced = FuncExpr(FuncPow, 1, replacement, newExprOf(NewTerm(v.Power())))
+				}
+			} else {
+				vs = append(vs, v)
+			}
+		}
+		out = Add(out, Mul(newExprOf(newTermC(coeffOf(term), vs...)), replaced))
+		return true
+	})
+	return out
+}
+
+// PartialEval folds the variables known to m into the coefficients and
+// keeps the others, e.g. x*y + z with x=2 gives 2(y) + 1(z). Functions
+// whose arguments become constant are folded as well. A factor undefined at
+// its value is an error as in ValueOfExpr, e.g. x^-1 at x=0 is an
+// ErrDivisionByZero and x^0.5 at x=-4 an ErrDomain.
+func PartialEval(e IExpression, m IValuation) (IExpression, error) {
+	var terms TermList
+	var err error
+	e.EachTerm(func(term ITerm) bool {
+		c := coeffOf(term)
+		vs := make(VariableList, 0, len(term.Vars()))
+		for _, v := range term.Vars() {
+			var f float64
+			if fn, isFunc := v.(IFunction); isFunc {
+				var folded IFunction
+				if f, folded, err = partialEvalFunc(fn, m); nil != err {
+					return false
+				} else if nil != folded {
+					vs = append(vs, folded)
+					continue
+				}
+			} else if value, has := m.Get(v.Name()); has {
+				f = value
+			} else {
+				vs = append(vs, v)
+				continue
+			}
+			if f, err = powOfFactor(v, f); nil != err {
+				return false
+			}
+			c *= complex(f, 0)
+		}
+		terms = append(terms, newTermC(c, vs...))
+		return true
+	})
+	if nil != err {
+		return nil, err
+	}
+	return newExprOf(terms...), nil
+}
+
+// partialEvalFunc returns the value of fn without its power if its
+// arguments evaluate to constants, otherwise fn with evaluated arguments.
+func partialEvalFunc(fn IFunction, m IValuation) (float64, IFunction, error) {
+	args := make([]IExpression, len(fn.Args()))
+	values := make([]float64, len(args))
+	bConst := true
+	for i, arg := range fn.Args() {
+		var isConst bool
+		var err error
+		if args[i], err = PartialEval(arg, m); nil != err {
+			return 0, nil, err
+		}
+		values[i], isConst = ConstantOf(args[i])
+		bConst = bConst && isConst
+	}
+
+	if !bConst {
+		return 0, NewFuncN(fn.Kind(), fn.Power(), args...), nil
+	}
+	f, err := fn.Kind().Apply(values...)
+	return f, nil, err
+}
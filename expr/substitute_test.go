@@ -0,0 +1,70 @@
+package expr
+
+import (
+	"testing"
+
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestSubstitute(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e := mustParse("x^2*y + 3x + 1")
+	assert.Equal("4 + 3(t) + 2(t*y) + 1(t^2*y) + 1(y)", Substitute(e, "x", mustParse("t + 1")).String())
+	assert.Equal("1 + 3(x) + 2(x^2)", Substitute(e, "y", mustParse("2")).String())
+	assert.Equal(e.String(), Substitute(e, "z", mustParse("2")).String())
+	assert.Equal("1 + 3(x) + 1(x^2*y)", e.String())
+
+	// a power that cannot be expanded becomes a pow node
+	e = Substitute(mustParse("x^0.5"), "x", mustParse("t + 1"))
+	assert.Equal("1(pow(1 + t, 0.5))", e.String())
+	v, err := ValueOfExpr(e, Valuation{"t": 3})
+	assert.Nil(err)
+	assert.Equal(2.0, v)
+	e = Substitute(mustParse("2y/x"), "x", mustParse("t - 1"))
+	v, err = ValueOfExpr(e, Valuation{"t": 5, "y": 2})
+	assert.Nil(err)
+	assert.Equal(1.0, v)
+}
+
+func TestPartialEval(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	e := mustParse("x*y^2 + 3x + z - 1")
+	assert.Equal("-1 + 7(x) + 1(z)", mustPartialEval(e, Valuation{"y": 2}).String())
+	assert.Equal("3 + 1(z)", mustPartialEval(e, Valuation{"x": 1, "y": 1}).String())
+	assert.Equal(e.String(), mustPartialEval(e, Valuation{}).String())
+
+	v, err := ValueOfExpr(mustPartialEval(e, Valuation{"y": 2}), Valuation{"x": 1, "z": 1})
+	assert.Nil(err)
+	assert.Equal(7.0, v)
+
+	e = mustParse("sin(x*y)*z + y")
+	assert.Equal("2 + 1(sin(2*x)*z)", mustPartialEval(e, Valuation{"y": 2}).String())
+	assert.Equal("2", mustPartialEval(e, Valuation{"x": 0, "y": 2}).String())
+
+	// undefined where ValueOfExpr is
+	for _, tt := range []struct {
+		s string
+		m Valuation
+	}{
+		{"x^-1 + y", Valuation{"x": 0}},
+		{"x^0.5*y", Valuation{"x": -4}},
+		{"log(x)*y", Valuation{"x": -1}},
+		{"sin(x)^-1 + y", Valuation{"x": 0}},
+		{"y*sqrt(x + z)", Valuation{"x": -1, "z": -1}},
+	} {
+		_, err := ValueOfExpr(mustParse(tt.s), Valuation{"x": tt.m["x"], "y": 1, "z": tt.m["z"]})
+		assert.NotNil(err, tt.s)
+		_, err = PartialEval(mustParse(tt.s), tt.m)
+		assert.NotNil(err, tt.s)
+	}
+}
+
+func mustPartialEval(e IExpression, m IValuation) IExpression {
+	out, err := PartialEval(e, m)
+	if nil != err {
+		panic(err)
+	}
+	return out
+}
@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
-
-	"github.com/noypi/math0"
 )
 
 type ITerm interface {
@@ -61,50 +59,18 @@ func (this _Term) Var(name string) (v IVariable) {
 		v = this.vars[i]
 	}
 
-	return nil
+	return
 }
 
 func (this *_Term) SetC(c float64) {
 	this.c = c
 }
 
+// SetVars sorts vs by name and merges the powers of repeated variables.
 func (this *_Term) SetVars(vs ...IVariable) {
 	this.key = nil
 	this.powertotal = nil
-	this.vars = this.vars[:0]
-	if 0 == len(vs) {
-		return
-	}
-
-	if !math0.IsApproxEqual(vs[0].Power(), 0.0) {
-		this.vars = append(this.vars, vs[0])
-	}
-	if 1 == len(vs) {
-		return
-	}
-
-	for i, _ := range vs[1:] {
-		if math0.IsApproxEqual(vs[i].Power(), 0.0) {
-			continue
-		}
-
-		jprev := len(this.vars) - 1
-		if vs[i].Name() == this.vars[jprev].Name() {
-			this.vars[jprev] = this.vars[jprev].AddPower(vs[i].Power())
-			if math0.IsApproxEqual(this.vars[jprev].Power(), 0.0) {
-				// removed if 0.0
-				this.vars = this.vars[:jprev]
-			}
-		} else {
-			this.vars = append(this.vars, vs[i])
-		}
-	}
-	if 1 < len(vs) {
-		sort.Slice(vs, func(i, j int) bool {
-			return vs[i].Name() < vs[j].Name()
-		})
-	}
-	this.vars = vs
+	this.vars = mulVars(vs, nil)
 }
 
 func (this *_Term) Key() string {
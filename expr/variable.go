@@ -23,11 +23,9 @@ type _Variable struct {
 
 type VariableList []IVariable
 
+// NewVarN returns name^power, the power may be negative or fractional.
 func NewVarN(name string, power float64) IVariable {
-	if 1 <= power {
-		return &_Variable{name: name, power: power}
-	}
-	return &_Variable{}
+	return &_Variable{name: name, power: power}
 }
 
 func NewVar(name string) IVariable {
@@ -54,44 +52,13 @@ func (this _Variable) String() string {
 	return fmt.Sprintf("%s^%s", this.name, ToTrimZero(this.power))
 }
 
-func (this VariableList) Simplify() (out VariableList) {
+// Simplify sorts by name, merges the powers of repeated variables and drops
+// zero powers.
+func (this VariableList) Simplify() VariableList {
 	if this.IsSimplified() {
 		return this
 	}
-
-	vs := this
-	if 1 >= len(vs) {
-		return this
-	}
-
-	if !vs.IsSorted() {
-		sort.Slice(vs, vs.Less)
-	}
-
-	out = VariableList{vs[0]}
-	outPrev := vs[0]
-	for i := 1; i < len(vs); i++ {
-		if math0.IsApproxEqual(vs[i].Power(), 0.0) {
-			continue
-		}
-
-		if outPrev.Name() == vs[i].Name() {
-			outPrev = outPrev.AddPower(vs[i].Power())
-			if math0.IsApproxEqual(outPrev.Power(), 0.0) {
-				out = out[:len(out)-1]
-			} else {
-				out[len(out)-1] = outPrev
-			}
-		} else {
-			if !math0.IsApproxEqual(vs[i].Power(), 0.0) {
-				out = append(out, vs[i])
-				outPrev = out[len(out)-1]
-			}
-		}
-
-	}
-
-	return out
+	return mulVars(this, nil)
 }
 
 func (this VariableList) Less(i, j int) bool {
@@ -99,12 +66,11 @@ func (this VariableList) Less(i, j int) bool {
 }
 
 func (this VariableList) IsSimplified() bool {
-	if 1 >= len(this) {
-		return true
-	}
-
-	for i, iprev := 1, 0; i < len(this); i, iprev = i+1, iprev+1 {
-		if !this.Less(iprev, i) {
+	for i, v := range this {
+		if math0.IsApproxEqual(v.Power(), 0.0) {
+			return false
+		}
+		if 0 < i && !this.Less(i-1, i) {
 			return false
 		}
 	}
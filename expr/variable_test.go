@@ -28,6 +28,9 @@ func TestVariable(t *testing.T) {
 	}
 	assert.Equal("z", varlist.Key())
 
+	varlist = MustVars("y^0.5", "x^-2", "x", "y^-0.5", "z")
+	assert.Equal("x^-1*z", varlist.Key())
+
 }
 
 func BenchmarkVariableStringOneVars(b *testing.B) {
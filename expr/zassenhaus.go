@@ -0,0 +1,380 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+)
+
+// zassenhaus factors a primitive square-free polynomial: it is factored mod
+// a small prime p, the factors are lifted to p^k by Hensel's lemma, past
+// twice the Mignotte bound on the coefficients of a factor, and products of
+// them are tried as factors over the integers, the smallest first.
+func zassenhaus(f _IntPoly) ([]_IntPoly, error) {
+	p, factors := choosePrime(f)
+	if 0 == p {
+		return nil, fmt.Errorf("factor: no prime keeps %v square-free", f)
+	}
+	if 1 == len(factors) {
+		return []_IntPoly{f}, nil
+	}
+
+	bound := mignotteBound(f)
+	bound.Lsh(bound, 1)
+	k, modulus := 1, big.NewInt(int64(p))
+	for modulus.Cmp(bound) <= 0 {
+		modulus.Mul(modulus, big.NewInt(int64(p)))
+		k++
+	}
+	lifted := henselLiftAll(f, factors, p, k)
+
+	var out []_IntPoly
+	for s := 1; 2*s <= len(lifted); s++ {
+		idx := make([]int, s)
+		for i := range idx {
+			idx[i] = i
+		}
+		for {
+			g := _IntPoly{f.lc()}
+			for _, i := range idx {
+				g = g.mul(lifted[i]).symmetricMod(modulus)
+			}
+			g = g.primitive()
+			if q, isFactor := f.divExact(g); isFactor {
+				out = append(out, g)
+				f = q
+				lifted = without(lifted, idx)
+				if 2*s > len(lifted) {
+					break
+				}
+				for i := range idx {
+					idx[i] = i
+				}
+				continue
+			}
+			if !nextCombination(idx, len(lifted)) {
+				break
+			}
+		}
+	}
+	return append(out, f.primitive()), nil
+}
+
+// choosePrime returns the prime, of the first few that keep f square-free,
+// with the fewest factors of f, and the monic factors mod that prime.
+func choosePrime(f _IntPoly) (best _Zp, factors [][]int64) {
+	rnd := rand.New(rand.NewSource(1))
+	tried := 0
+	for n := int64(3); n < 1<<15 && 5 > tried; n += 2 {
+		if !isSmallPrime(n) {
+			continue
+		}
+		p := _Zp(n)
+		fp := p.reduce(f)
+		if len(fp) != len(f) || 0 < len(p.gcd(fp, p.derivative(fp)))-1 {
+			continue
+		}
+		tried++
+		fs := p.factor(p.monic(fp), rnd)
+		if nil == factors || len(fs) < len(factors) {
+			best, factors = p, fs
+		}
+		if 1 == len(fs) {
+			break
+		}
+	}
+	return
+}
+
+func isSmallPrime(n int64) bool {
+	for d := int64(2); d*d <= n; d++ {
+		if 0 == n%d {
+			return false
+		}
+	}
+	return 2 <= n
+}
+
+// mignotteBound bounds the coefficients of lc(f)/lc(g) g, g any factor of
+// f, by |lc(f)| 2^n ||f||.
+func mignotteBound(f _IntPoly) *big.Int {
+	norm := new(big.Int)
+	for _, c := range f {
+		norm.Add(norm, new(big.Int).Mul(c, c))
+	}
+	norm.Sqrt(norm)
+	norm.Add(norm, big.NewInt(1))
+	norm.Mul(norm, new(big.Int).Abs(f.lc()))
+	return norm.Lsh(norm, uint(f.degree()))
+}
+
+// henselLiftAll lifts f = lc(f) factors[0] ... mod p to the same mod p^k,
+// the lifted factors monic.
+func henselLiftAll(f _IntPoly, factors [][]int64, p _Zp, k int) []_IntPoly {
+	if 1 == len(factors) {
+		// only reached for the monic rest of a lift
+		return []_IntPoly{f}
+	}
+	rest := factors[1]
+	for _, h := range factors[2:] {
+		rest = p.mul(rest, h)
+	}
+	g, h := henselLift(f, factors[0], rest, p, k)
+	return append([]_IntPoly{g}, henselLiftAll(h, factors[1:], p, k)...)
+}
+
+// henselLift lifts f = lc(f) g h mod p, g and h monic and coprime, to
+// f = lc(f) G H mod p^k, one power of p at a time.
+func henselLift(f _IntPoly, g, h []int64, p _Zp, k int) (G, H _IntPoly) {
+	_, s, t := p.extGCD(g, h)
+	lcInv := p.inverse(p.reduce(_IntPoly{f.lc()})[0])
+	G, H = intPolyOf(g), intPolyOf(h)
+
+	pk := big.NewInt(int64(p))
+	for i := 1; i < k; i++ {
+		// f - lc G H = p^i e, and G + p^i dg, H + p^i dh take it to p^(i+1)
+		// when g dh + h dg = e/lc mod p
+		e, _ := f.sub(G.mul(H).mulInt(f.lc())).divExact(_IntPoly{pk})
+		e1 := p.scale(p.reduce(e), lcInv)
+		q, dg := p.divMod(p.mul(t, e1), g)
+		dh := p.add(p.mul(s, e1), p.mul(q, h))
+		G = G.add(intPolyOf(dg).mulInt(pk))
+		H = H.add(intPolyOf(dh).mulInt(pk))
+		pk.Mul(pk, big.NewInt(int64(p)))
+	}
+	return
+}
+
+func (this _IntPoly) symmetricMod(m *big.Int) _IntPoly {
+	half := new(big.Int).Rsh(m, 1)
+	out := make(_IntPoly, len(this))
+	for i, c := range this {
+		out[i] = new(big.Int).Mod(c, m)
+		if 0 < out[i].Cmp(half) {
+			out[i].Sub(out[i], m)
+		}
+	}
+	return out.trim()
+}
+
+func intPolyOf(a []int64) _IntPoly {
+	out := make(_IntPoly, len(a))
+	for i, c := range a {
+		out[i] = big.NewInt(c)
+	}
+	return out
+}
+
+func without(fs []_IntPoly, idx []int) []_IntPoly {
+	out := make([]_IntPoly, 0, len(fs)-len(idx))
+	for i, f := range fs {
+		skip := false
+		for _, j := range idx {
+			skip = skip || i == j
+		}
+		if !skip {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// nextCombination advances idx, increasing indices below n, to the next
+// combination in lexicographic order, false after the last.
+func nextCombination(idx []int, n int) bool {
+	for i := len(idx) - 1; 0 <= i; i-- {
+		if idx[i] < n-len(idx)+i {
+			idx[i]++
+			for j := i + 1; j < len(idx); j++ {
+				idx[j] = idx[j-1] + 1
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// _Zp is arithmetic on polynomials mod an odd prime, as coefficients in
+// [0, p) indexed by power and without leading zeros.
+type _Zp int64
+
+func (this _Zp) reduce(f _IntPoly) []int64 {
+	m := big.NewInt(int64(this))
+	out := make([]int64, len(f))
+	for i, c := range f {
+		out[i] = new(big.Int).Mod(c, m).Int64()
+	}
+	return this.trim(out)
+}
+
+func (this _Zp) trim(a []int64) []int64 {
+	for 0 < len(a) && 0 == a[len(a)-1] {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+func (this _Zp) inverse(a int64) int64 {
+	// a^(p-2) by Fermat
+	out, base := int64(1), a%int64(this)
+	for n := int64(this) - 2; 0 < n; n >>= 1 {
+		if 1 == n&1 {
+			out = out * base % int64(this)
+		}
+		base = base * base % int64(this)
+	}
+	return out
+}
+
+func (this _Zp) add(a, b []int64) []int64 {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	out := append([]int64{}, a...)
+	for i, c := range b {
+		out[i] = (out[i] + c) % int64(this)
+	}
+	return this.trim(out)
+}
+
+func (this _Zp) sub(a, b []int64) []int64 {
+	return this.add(a, this.scale(b, int64(this)-1))
+}
+
+func (this _Zp) scale(a []int64, c int64) []int64 {
+	out := make([]int64, len(a))
+	for i, x := range a {
+		out[i] = x * c % int64(this)
+	}
+	return this.trim(out)
+}
+
+func (this _Zp) mul(a, b []int64) []int64 {
+	if 0 == len(a) || 0 == len(b) {
+		return nil
+	}
+	out := make([]int64, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			out[i+j] = (out[i+j] + x*y) % int64(this)
+		}
+	}
+	return this.trim(out)
+}
+
+func (this _Zp) monic(a []int64) []int64 {
+	return this.scale(a, this.inverse(a[len(a)-1]))
+}
+
+func (this _Zp) divMod(a, b []int64) (q, r []int64) {
+	r = append([]int64{}, a...)
+	if len(a) < len(b) {
+		return nil, r
+	}
+	q = make([]int64, len(a)-len(b)+1)
+	inv := this.inverse(b[len(b)-1])
+	for i := len(q) - 1; 0 <= i; i-- {
+		c := r[i+len(b)-1] * inv % int64(this)
+		q[i] = c
+		for j, y := range b {
+			r[i+j] = (r[i+j] + (int64(this)-c)*y) % int64(this)
+		}
+	}
+	return this.trim(q), this.trim(r)
+}
+
+// gcd is monic, or nil if both a and b are zero.
+func (this _Zp) gcd(a, b []int64) []int64 {
+	for 0 < len(b) {
+		_, r := this.divMod(a, b)
+		a, b = b, r
+	}
+	if 0 == len(a) {
+		return nil
+	}
+	return this.monic(a)
+}
+
+// extGCD returns the monic gcd g of a and b, and s and t with
+// s a + t b = g.
+func (this _Zp) extGCD(a, b []int64) (g, s, t []int64) {
+	s0, s1 := []int64{1}, []int64(nil)
+	t0, t1 := []int64(nil), []int64{1}
+	for 0 < len(b) {
+		q, r := this.divMod(a, b)
+		a, b = b, r
+		s0, s1 = s1, this.sub(s0, this.mul(q, s1))
+		t0, t1 = t1, this.sub(t0, this.mul(q, t1))
+	}
+	inv := this.inverse(a[len(a)-1])
+	return this.scale(a, inv), this.scale(s0, inv), this.scale(t0, inv)
+}
+
+func (this _Zp) derivative(a []int64) []int64 {
+	if 1 >= len(a) {
+		return nil
+	}
+	out := make([]int64, len(a)-1)
+	for i := range out {
+		out[i] = a[i+1] * int64(i+1) % int64(this)
+	}
+	return this.trim(out)
+}
+
+// powMod is a^n mod m.
+func (this _Zp) powMod(a []int64, n *big.Int, m []int64) []int64 {
+	out := []int64{1}
+	_, a = this.divMod(a, m)
+	for i := n.BitLen() - 1; 0 <= i; i-- {
+		_, out = this.divMod(this.mul(out, out), m)
+		if 1 == n.Bit(i) {
+			_, out = this.divMod(this.mul(out, a), m)
+		}
+	}
+	return out
+}
+
+// factor returns the monic irreducible factors of a monic square-free f,
+// by distinct degree then equal degree factorization (Cantor-Zassenhaus).
+func (this _Zp) factor(f []int64, rnd *rand.Rand) (out [][]int64) {
+	x := []int64{0, 1}
+	h := x
+	for d := 1; 2*d <= len(f)-1; d++ {
+		h = this.powMod(h, big.NewInt(int64(this)), f)
+		if g := this.gcd(this.sub(h, x), f); 1 < len(g) {
+			out = append(out, this.splitEqualDegree(g, d, rnd)...)
+			f, _ = this.divMod(f, g)
+			_, h = this.divMod(h, f)
+		}
+	}
+	if 1 < len(f) {
+		out = append(out, f)
+	}
+	return
+}
+
+// splitEqualDegree splits f, a product of irreducibles of degree d.
+func (this _Zp) splitEqualDegree(f []int64, d int, rnd *rand.Rand) [][]int64 {
+	if len(f)-1 == d {
+		return [][]int64{f}
+	}
+
+	// (p^d - 1)/2
+	n := new(big.Int).Exp(big.NewInt(int64(this)), big.NewInt(int64(d)), nil)
+	n.Rsh(n, 1)
+	for {
+		a := make([]int64, len(f)-1)
+		for i := range a {
+			a[i] = rnd.Int63n(int64(this))
+		}
+		a = this.trim(a)
+		if 1 >= len(a) {
+			continue
+		}
+		g := this.gcd(this.sub(this.powMod(a, n, f), []int64{1}), f)
+		if 1 < len(g) && len(g) < len(f) {
+			q, _ := this.divMod(f, g)
+			return append(this.splitEqualDegree(g, d, rnd), this.splitEqualDegree(q, d, rnd)...)
+		}
+	}
+}
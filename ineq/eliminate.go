@@ -0,0 +1,300 @@
+package ineq
+
+import (
+	"math/big"
+)
+
+// Eliminate projects sys onto its other variables by Fourier-Motzkin
+// elimination: each inequality with a positive coefficient of varname is
+// combined with each one with a negative coefficient, strict if either is.
+// Redundant inequalities are pruned: those without variables that hold,
+// of those with the same left side, up to scale, all but the tightest, and
+// those that Imbert's rules show redundant from the inequalities of
+// NewSystem they were combined from, see prune.
+func Eliminate(sys *System, varname string) *System {
+	o := &System{origins: sys.origins}
+	var pos, neg []_Row
+	for _, row := range sys.rows {
+		switch coeffOf(row, varname).Sign() {
+		case 1:
+			pos = append(pos, row)
+		case -1:
+			neg = append(neg, row)
+		default:
+			o.rows = append(o.rows, row)
+		}
+	}
+
+	for _, p := range pos {
+		for _, n := range neg {
+			// -b p + a n, a > 0 the coefficient in p and b < 0 in n
+			a, b := p.coeffs[varname], n.coeffs[varname]
+			row := p.scale(new(big.Rat).Neg(b))
+			for name, c := range n.coeffs {
+				sum := new(big.Rat).Mul(c, a)
+				sum.Add(sum, coeffOf(row, name))
+				if 0 == sum.Sign() {
+					delete(row.coeffs, name)
+				} else {
+					row.coeffs[name] = sum
+				}
+			}
+			row.c.Add(row.c, new(big.Rat).Mul(n.c, a))
+			row.strict = p.strict || n.strict
+			row.histories = nil
+			for _, hp := range p.histories {
+				for _, hn := range n.histories {
+					row.histories = append(row.histories, unionOf(hp, hn))
+				}
+			}
+			o.rows = append(o.rows, row)
+		}
+	}
+	o.prune()
+	return o
+}
+
+// Feasible reports whether sys has a solution, and one if it has. Every
+// variable is eliminated in turn, then each is given a value between its
+// bounds in the reverse order. The arithmetic is exact, only the witness is
+// rounded to float64.
+func Feasible(sys *System) (bool, map[string]float64) {
+	vars := sys.Vars()
+	systems := []*System{sys}
+	for _, name := range vars {
+		systems = append(systems, Eliminate(systems[len(systems)-1], name))
+	}
+	for _, row := range systems[len(vars)].rows {
+		if !row.holds() {
+			return false, nil
+		}
+	}
+
+	values := make(map[string]*big.Rat, len(vars))
+	for i := len(vars) - 1; 0 <= i; i-- {
+		name := vars[i]
+		var lo, hi *big.Rat // nil if unbounded
+		loStrict, hiStrict := false, false
+		for _, row := range systems[i].rows {
+			a := coeffOf(row, name)
+			if 0 == a.Sign() {
+				continue
+			}
+			rest := new(big.Rat).Set(row.c)
+			for other, b := range row.coeffs {
+				if other != name {
+					rest.Add(rest, new(big.Rat).Mul(b, values[other]))
+				}
+			}
+			// a x + rest <= 0 bounds x by -rest/a, an equal bound is strict
+			// if either is
+			bound := rest.Neg(rest)
+			bound.Quo(bound, a)
+			if 0 < a.Sign() {
+				switch cmp := compare(bound, hi, 1); {
+				case 0 > cmp:
+					hi, hiStrict = bound, row.strict
+				case 0 == cmp:
+					hiStrict = hiStrict || row.strict
+				}
+			} else {
+				switch cmp := compare(bound, lo, -1); {
+				case 0 < cmp:
+					lo, loStrict = bound, row.strict
+				case 0 == cmp:
+					loStrict = loStrict || row.strict
+				}
+			}
+		}
+		values[name] = between(lo, loStrict, hi, hiStrict)
+	}
+
+	witness := make(map[string]float64, len(vars))
+	for name, value := range values {
+		witness[name], _ = value.Float64()
+	}
+	return true, witness
+}
+
+// between picks 0 if it is within the bounds, else a bound or a point
+// inside them. A nil bound is infinite.
+func between(lo *big.Rat, loStrict bool, hi *big.Rat, hiStrict bool) *big.Rat {
+	zero := new(big.Rat)
+	if inside(zero, lo, loStrict, -1) && inside(zero, hi, hiStrict, 1) {
+		return zero
+	}
+
+	one := big.NewRat(1, 1)
+	switch {
+	case nil != lo && nil != hi && (loStrict || hiStrict):
+		mid := new(big.Rat).Add(lo, hi)
+		return mid.Quo(mid, big.NewRat(2, 1))
+	case nil != lo && loStrict:
+		return new(big.Rat).Add(lo, one)
+	case nil != lo:
+		return lo
+	case hiStrict:
+		return new(big.Rat).Sub(hi, one)
+	}
+	return hi
+}
+
+// inside reports whether x is on the inner side of a lower (side -1) or an
+// upper (side 1) bound.
+func inside(x, bound *big.Rat, isStrict bool, side int) bool {
+	if nil == bound {
+		return true
+	}
+	cmp := side * bound.Cmp(x)
+	return 0 < cmp || (0 == cmp && !isStrict)
+}
+
+// compare compares bound with current, a nil current being infinite on
+// the given side.
+func compare(bound, current *big.Rat, side int) int {
+	if nil == current {
+		return -side
+	}
+	return bound.Cmp(current)
+}
+
+// coeffOf is the coefficient of name in row, 0 if it has none.
+func coeffOf(row _Row, name string) *big.Rat {
+	if a, has := row.coeffs[name]; has {
+		return a
+	}
+	return new(big.Rat)
+}
+
+// prune normalizes the rows and drops the redundant ones: besides those
+// implied by a single other row, by Imbert's acceleration theorems those
+// each of whose histories either has more rows than one plus the variables
+// effectively eliminated, those in its rows but no longer in the row
+// (Chernikov's rule), or contains another history (Kohler's rule). A row
+// keeps the histories of the looser rows it replaces, as the rules rely on
+// their combinations being kept. Without the rules the rows grow
+// exponentially with each elimination.
+func (this *System) prune() {
+	rows := make([]_Row, 0, len(this.rows))
+	index := map[string]int{}
+	bContradiction := false
+	for _, row := range this.rows {
+		row = row.normalized()
+		if 0 == len(row.coeffs) {
+			if !row.holds() && !bContradiction {
+				rows = append(rows, row)
+				bContradiction = true
+			}
+			continue
+		}
+		if row.histories = this.chernikov(row); 0 == len(row.histories) {
+			continue
+		}
+
+		key := row.key()
+		i, has := index[key]
+		cmp := 0
+		if has {
+			cmp = row.c.Cmp(rows[i].c)
+		}
+		switch {
+		case !has:
+			index[key] = len(rows)
+			rows = append(rows, row)
+		case 0 < cmp || (0 == cmp && row.strict && !rows[i].strict):
+			row.histories = append(append([][]int(nil), row.histories...), rows[i].histories...)
+			rows[i] = row
+		default:
+			rows[i].histories = append(append([][]int(nil), rows[i].histories...), row.histories...)
+		}
+	}
+	this.rows = kohler(rows)
+}
+
+// chernikov returns the histories of row within Chernikov's bound.
+func (this *System) chernikov(row _Row) (out [][]int) {
+	for _, h := range row.histories {
+		seen := map[string]bool{}
+		for _, i := range h {
+			for _, name := range this.origins[i] {
+				seen[name] = true
+			}
+		}
+		if len(h) <= 1+len(seen)-len(row.coeffs) {
+			out = append(out, h)
+		}
+	}
+	return
+}
+
+// kohler drops the histories containing another, and the rows left with
+// none.
+func kohler(rows []_Row) []_Row {
+	out := make([]_Row, 0, len(rows))
+	for _, row := range rows {
+		if 0 == len(row.coeffs) {
+			out = append(out, row)
+			continue
+		}
+
+		var histories [][]int
+	next:
+		for k, h := range row.histories {
+			for _, other := range rows {
+				if 0 == len(other.coeffs) {
+					continue
+				}
+				for _, g := range other.histories {
+					if len(g) < len(h) && isSubset(g, h) {
+						continue next
+					}
+				}
+			}
+			for _, g := range row.histories[:k] {
+				if len(g) == len(h) && isSubset(g, h) {
+					continue next
+				}
+			}
+			histories = append(histories, h)
+		}
+		if 0 < len(histories) {
+			row.histories = histories
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// unionOf merges the ascending a and b.
+func unionOf(a, b []int) []int {
+	out := make([]int, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			out = append(out, a[i])
+			i++
+		case i == len(a) || b[j] < a[i]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i, j = i+1, j+1
+		}
+	}
+	return out
+}
+
+// isSubset reports whether the ascending a is within the ascending b.
+func isSubset(a, b []int) bool {
+	j := 0
+	for _, x := range a {
+		for j < len(b) && b[j] < x {
+			j++
+		}
+		if j == len(b) || b[j] != x {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package ineq_test
+
+import (
+	"testing"
+
+	"github.com/noypi/math0/expr"
+	. "github.com/noypi/math0/ineq"
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func mustSystem(ss ...string) (*System, []expr.IEquation) {
+	eqns := make([]expr.IEquation, len(ss))
+	for i, s := range ss {
+		eqn, err := expr.ParseEquation(s)
+		if nil != err {
+			panic(err)
+		}
+		eqns[i] = eqn
+	}
+	sys, err := NewSystem(eqns...)
+	if nil != err {
+		panic(err)
+	}
+	return sys, eqns
+}
+
+func TestEliminate(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	sys, _ := mustSystem("x + y <= 4", "x - y >= 0", "y > 1", "x < 3")
+	assert.Equal([]string{"x", "y"}, sys.Vars())
+
+	// 1 < y <= x < 3 and x + y <= 4
+	projected := Eliminate(sys, "x")
+	assert.Equal([]string{"y"}, projected.Vars())
+	assert.Equal("-1(y) < -1; 1(y) <= 2", projected.String())
+
+	projected = Eliminate(projected, "y")
+	assert.Equal("", projected.String())
+
+	// a strict bound and a loose one meeting at a point
+	sys, _ = mustSystem("x < 1", "x >= 1")
+	projected = Eliminate(sys, "x")
+	assert.Equal("0 < 0", projected.String())
+}
+
+func TestEliminate_Redundant(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// the box 0 <= x, y <= 1 with duplicates and looser bounds
+	sys, _ := mustSystem("x >= 0", "x <= 1", "2x <= 2", "x <= 5", "y >= 0", "y <= 1", "x + y <= 3", "x + y >= -3")
+	assert.Equal(6, len(sys.Equations()))
+
+	projected := Eliminate(sys, "x")
+	assert.Equal("-1(y) <= 0; 1(y) <= 1", projected.String())
+}
+
+func TestEliminate_Growth(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	// pruning only parallel rows, the fifth projection has over 150000
+	sys, eqns := mustSystem(
+		"x2 + x3 - x4 + x5 + x6 + x7 < -2",
+		"x1 + x2 - x3 + x4 + x6 <= -2",
+		"x1 - x2 - x3 + x4 + x5 - x6 + x7 < 2",
+		"-x1 - x2 - x3 - x5 + x6 < -1",
+		"x1 - x2 + x3 + x4 + x5 - x7 < 2",
+		"-x1 - x2 + x3 - x4 - x5 - x6 - x7 > 1",
+		"-x1 + x2 + x3 - x6 - x7 < 0",
+		"-x1 + x2 - x3 > 2",
+		"-x1 + x3 + x4 - x5 + x6 < 4",
+		"-x1 - x2 - x5 - x6 < -2",
+	)
+	projected := sys
+	for _, name := range []string{"x1", "x2", "x3", "x4", "x5"} {
+		projected = Eliminate(projected, name)
+		assert.True(40 >= len(projected.Equations()), "%s: %d rows", name, len(projected.Equations()))
+	}
+	assert.Equal([]string{"x6", "x7"}, projected.Vars())
+	bFeasible, _ := Feasible(projected)
+	assert.True(bFeasible)
+
+	bFeasible, witness := Feasible(sys)
+	if assert.True(bFeasible) {
+		for _, eqn := range eqns {
+			b, err := expr.IsEquationTrue(eqn, expr.Valuation(witness))
+			assert.Nil(err)
+			assert.True(b, "%s at %v", eqn, witness)
+		}
+	}
+}
+
+func TestFeasible(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for _, ss := range [][]string{
+		{"x + y <= 4", "x - y >= 0", "y > 1", "x < 3"},
+		{"x > 0", "x < 1e-6"},
+		{"x + 2y - z <= 3", "z == 2x + 1", "y > x", "x >= 2", "y + z < 12"},
+		{"a + b + c + d >= 10", "a - b <= 1", "b - c <= 1", "c - d <= 1", "d < 2"},
+		{"x == 3", "y == x + 1"},
+		{"x >= 5"},
+		{"x < -5"},
+		// bounds far from 1; expr drops coefficients below math0.Epsilon,
+		// so x < 1e-10 is written 1e10x < 1
+		{"1000000x > 1", "1000000x < 1.0001"},
+		{"x > 0", "1e10x < 1"},
+		{"1e10x >= 1", "y == 3e9x"},
+	} {
+		sys, eqns := mustSystem(ss...)
+		bFeasible, witness := Feasible(sys)
+		if !assert.True(bFeasible, "%v", ss) {
+			continue
+		}
+		for _, eqn := range eqns {
+			b, err := expr.IsEquationTrue(eqn, expr.Valuation(witness))
+			assert.Nil(err)
+			assert.True(b, "%s at %v", eqn, witness)
+		}
+	}
+
+	for _, ss := range [][]string{
+		{"x < 1", "x >= 1"},
+		{"x > 0", "y > 0", "x + y <= 0"},
+		{"x + y == 1", "x + y == 2"},
+		{"a + b + c + d >= 10", "a - b <= 1", "b - c <= 1", "c - d <= 1", "d < 1"},
+		{"0 < x - x"},
+		{"x <= 0", "1e10x >= 1"},
+		{"1000000x >= 1.0001", "1000000x <= 1"},
+		// a row replaced by a tighter one is needed for the contradiction
+		{"-x1 + x2 + 2x3 >= 3", "2x1 - 2x2 - x4 == 4", "2x1 + 2x3 >= 1", "-x1 + x2 + 2x3 + 2x4 <= 3", "x1 - 2x2 - x4 < 0", "-2x1 - 2x2 - x3 - x4 > 0"},
+	} {
+		sys, _ := mustSystem(ss...)
+		bFeasible, witness := Feasible(sys)
+		assert.False(bFeasible, "%v", ss)
+		assert.Nil(witness)
+	}
+}
+
+func TestNewSystem_Errors(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for _, s := range []string{"x*y <= 1", "x^2 > 0", "sin(x) < 1", "x != 1"} {
+		eqn, err := expr.ParseEquation(s)
+		assert.Nil(err)
+		_, err = NewSystem(eqn)
+		assert.NotNil(err, s)
+	}
+
+	// a complex coefficient is a domain error rather than a nonlinear term
+	complexEqn := expr.Equation(expr.NewExpr(expr.NewComplexTerm(1i, expr.NewVar("x"))), expr.LEQ, nil)
+	_, err := NewSystem(complexEqn)
+	if assert.NotNil(err) {
+		assert.Contains(err.Error(), "complex coefficient")
+		assert.NotContains(err.Error(), "not linear")
+	}
+}
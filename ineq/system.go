@@ -0,0 +1,211 @@
+package ineq
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/noypi/math0"
+	"github.com/noypi/math0/expr"
+)
+
+// System is a conjunction of linear inequalities, each kept as
+// a·x + c <= 0, or < 0 if strict. Coefficients are exact fractions, read
+// from the equations with expr.RatOf, so that eliminating variables adds no
+// rounding errors.
+type System struct {
+	rows    []_Row
+	origins [][]string // the variables of each row of NewSystem
+}
+
+type _Row struct {
+	coeffs map[string]*big.Rat
+	c      *big.Rat
+	strict bool
+
+	// histories are the sets of rows of NewSystem combined into this row,
+	// by index and ascending, one for it and one for each looser row with
+	// the same left side that it replaced.
+	histories [][]int
+}
+
+// NewSystem builds a system from LEQ, GEQ, Lesser, Greater and EQ
+// equations linear in their variables, an EQ counting as two inequalities.
+func NewSystem(eqns ...expr.IEquation) (*System, error) {
+	o := new(System)
+	for i, eqn := range eqns {
+		f := expr.Sub(eqn.Left(), eqn.Right())
+		row, err := rowOf(f, i, eqn)
+		if nil != err {
+			return nil, err
+		}
+
+		switch eqn.Relation() {
+		case expr.LEQ, expr.Lesser:
+			row.strict = expr.Lesser == eqn.Relation()
+			o.add(row)
+		case expr.GEQ, expr.Greater:
+			row = row.scale(big.NewRat(-1, 1))
+			row.strict = expr.Greater == eqn.Relation()
+			o.add(row)
+		case expr.EQ:
+			o.add(row)
+			o.add(row.scale(big.NewRat(-1, 1)))
+		default:
+			return nil, fmt.Errorf("ineq: equation %d (%s) is not an inequality", i, eqn)
+		}
+	}
+	o.prune()
+	return o, nil
+}
+
+// add appends a row of NewSystem, its history being its own index.
+func (this *System) add(row _Row) {
+	row.histories = [][]int{{len(this.rows)}}
+	this.rows = append(this.rows, row)
+	this.origins = append(this.origins, row.vars())
+}
+
+func rowOf(f expr.IExpression, i int, eqn expr.IEquation) (row _Row, err error) {
+	row.coeffs, row.c = map[string]*big.Rat{}, new(big.Rat)
+	f.EachTerm(func(term expr.ITerm) bool {
+		if ct, isComplex := term.(expr.IComplexTerm); isComplex && 0 != imag(ct.CC()) {
+			err = expr.ErrDomain(fmt.Errorf("ineq: equation %d (%s): %s has a complex coefficient", i, eqn, term))
+			return false
+		}
+		c := expr.RatOf(term.C())
+		if nil == c {
+			err = expr.ErrDomain(fmt.Errorf("ineq: equation %d (%s): %s has no finite coefficient", i, eqn, term))
+			return false
+		}
+		vs := term.Vars()
+		if 0 == len(vs) {
+			row.c.Add(row.c, c)
+			return true
+		}
+		_, isFunc := vs[0].(expr.IFunction)
+		if 1 < len(vs) || isFunc || !math0.IsApproxEqual(vs[0].Power(), 1.0) {
+			err = expr.ErrNotLinear(fmt.Errorf("ineq: equation %d (%s): %s is not linear", i, eqn, term))
+			return false
+		}
+		if a, has := row.coeffs[vs[0].Name()]; has {
+			a.Add(a, c)
+		} else {
+			row.coeffs[vs[0].Name()] = c
+		}
+		return true
+	})
+	return
+}
+
+// Vars returns the names of the variables of the system, sorted.
+func (this System) Vars() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, row := range this.rows {
+		for name := range row.coeffs {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Equations returns the inequalities as a·x <= -c or a·x < -c.
+func (this System) Equations() []expr.IEquation {
+	out := make([]expr.IEquation, 0, len(this.rows))
+	for _, row := range this.rows {
+		left := expr.TermList{}
+		for _, name := range row.vars() {
+			v := expr.EqnBuilder_VarConstructor(name, 1)
+			a, _ := row.coeffs[name].Float64()
+			left = append(left, expr.EqnBuilder_TermConstructor(a, v))
+		}
+		if 0 == len(left) {
+			left = append(left, expr.EqnBuilder_TermConstructor(0))
+		}
+		rel := expr.LEQ
+		if row.strict {
+			rel = expr.Lesser
+		}
+		bound, _ := new(big.Rat).Neg(row.c).Float64()
+		right := expr.EqnBuilder_ExprConstructor(expr.EqnBuilder_TermConstructor(bound))
+		out = append(out, expr.Equation(expr.EqnBuilder_ExprConstructor(left...), rel, right))
+	}
+	return out
+}
+
+func (this System) String() string {
+	buf := bytes.NewBufferString("")
+	for i, eqn := range this.Equations() {
+		if 0 < i {
+			buf.WriteString("; ")
+		}
+		buf.WriteString(eqn.String())
+	}
+	return buf.String()
+}
+
+func (this _Row) vars() []string {
+	names := make([]string, 0, len(this.coeffs))
+	for name := range this.coeffs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (this _Row) scale(f *big.Rat) _Row {
+	o := this
+	o.coeffs, o.c = make(map[string]*big.Rat, len(this.coeffs)), new(big.Rat).Mul(this.c, f)
+	for name, a := range this.coeffs {
+		o.coeffs[name] = new(big.Rat).Mul(a, f)
+	}
+	return o
+}
+
+// normalized drops zero coefficients and scales the largest to 1.
+func (this _Row) normalized() _Row {
+	largest := new(big.Rat)
+	for _, a := range this.coeffs {
+		if abs := new(big.Rat).Abs(a); 0 < abs.Cmp(largest) {
+			largest = abs
+		}
+	}
+	if 0 == largest.Sign() {
+		o := this
+		o.coeffs = map[string]*big.Rat{}
+		return o
+	}
+	o := this.scale(new(big.Rat).Inv(largest))
+	for name, a := range o.coeffs {
+		if 0 == a.Sign() {
+			delete(o.coeffs, name)
+		}
+	}
+	return o
+}
+
+// key identifies rows with the same left side.
+func (this _Row) key() string {
+	buf := bytes.NewBufferString("")
+	for _, name := range this.vars() {
+		buf.WriteString(name)
+		buf.WriteString(":")
+		buf.WriteString(this.coeffs[name].RatString())
+		buf.WriteString(" ")
+	}
+	return buf.String()
+}
+
+// holds reports whether a row without variables is true.
+func (this _Row) holds() bool {
+	if this.strict {
+		return 0 > this.c.Sign()
+	}
+	return 0 >= this.c.Sign()
+}
@@ -17,8 +17,8 @@ func aExampleSolverImpl_AddConstraint() {
 		return kiwi.Var(name)
 	}
 
-	eqn1 := expr.Eqn(expr.Terms("x"))(expr.EQ)(expr.Terms("5"))
-	eqn2 := expr.Eqn(expr.Terms("y"))(expr.EQ)(expr.Terms("10"))
+	eqn1 := expr.Eqn(expr.MustTerms("x"))(expr.EQ)(expr.MustTerms("5"))
+	eqn2 := expr.Eqn(expr.MustTerms("y"))(expr.EQ)(expr.MustTerms("10"))
 
 	solver := kiwi.Solver()
 
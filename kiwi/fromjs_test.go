@@ -59,10 +59,10 @@ func TestAddDelete1(t *testing.T) {
 
 	solver := Solver()
 
-	solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("x"))(expr.EQ)(expr.Terms("100")), Weak()))
+	solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.EQ)(expr.MustTerms("100")), Weak()))
 
-	c10expr := expr.Eqn(expr.Terms("x"))(expr.LEQ)(expr.Terms("10"))
-	c20expr := expr.Eqn(expr.Terms("x"))(expr.LEQ)(expr.Terms("20"))
+	c10expr := expr.Eqn(expr.MustTerms("x"))(expr.LEQ)(expr.MustTerms("10"))
+	c20expr := expr.Eqn(expr.MustTerms("x"))(expr.LEQ)(expr.MustTerms("20"))
 
 	c10 := NewConstraint(c10expr, Required())
 	c20 := NewConstraint(c20expr, Required())
@@ -81,7 +81,7 @@ func TestAddDelete1(t *testing.T) {
 	solver.UpdateVariables()
 	assert.Equal(100.0, x.Value())
 
-	c10exprAgain := expr.Eqn(expr.Terms("x"))(expr.LEQ)(expr.Terms("10"))
+	c10exprAgain := expr.Eqn(expr.MustTerms("x"))(expr.LEQ)(expr.MustTerms("10"))
 	c10again := NewConstraint(c10exprAgain, Required())
 	solver.AddConstraint(c10)
 	err := solver.AddConstraint(c10again)
@@ -110,11 +110,11 @@ func TestAddDelete2(t *testing.T) {
 
 	solver := Solver()
 
-	solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("x"))(expr.EQ)(expr.Terms("100.0")), Weak()))
-	solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("y"))(expr.EQ)(expr.Terms("120.0")), Strong()))
+	solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.EQ)(expr.MustTerms("100.0")), Weak()))
+	solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("y"))(expr.EQ)(expr.MustTerms("120.0")), Strong()))
 
-	c10 := NewConstraint(expr.Eqn(expr.Terms("x"))(expr.LEQ)(expr.Terms("10.0")), Required())
-	c20 := NewConstraint(expr.Eqn(expr.Terms("x"))(expr.LEQ)(expr.Terms("20.0")), Required())
+	c10 := NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.LEQ)(expr.MustTerms("10.0")), Required())
+	c20 := NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.LEQ)(expr.MustTerms("20.0")), Required())
 	solver.AddConstraint(c10)
 	solver.AddConstraint(c20)
 
@@ -128,7 +128,7 @@ func TestAddDelete2(t *testing.T) {
 	assert.Equal(20.0, solver.Var("x").Value())
 	assert.Equal(120.0, solver.Var("y").Value())
 
-	cxy := NewConstraint(expr.Eqn(expr.Terms("2x"))(expr.EQ)(expr.Terms("y")), Required())
+	cxy := NewConstraint(expr.Eqn(expr.MustTerms("2x"))(expr.EQ)(expr.MustTerms("y")), Required())
 	solver.AddConstraint(cxy)
 
 	solver.UpdateVariables()
@@ -156,10 +156,10 @@ func TestCasso1(t *testing.T) {
 	}
 
 	solver := Solver()
-	solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("x"))(expr.LEQ)(expr.Terms("y")), Required()))
-	solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("y"))(expr.EQ)(expr.Terms("x + 3")), Required()))
-	solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("x"))(expr.EQ)(expr.Terms("10")), Weak()))
-	solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("y"))(expr.EQ)(expr.Terms("10")), Weak()))
+	solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.LEQ)(expr.MustTerms("y")), Required()))
+	solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("y"))(expr.EQ)(expr.MustTerms("x + 3")), Required()))
+	solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.EQ)(expr.MustTerms("10")), Weak()))
+	solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("y"))(expr.EQ)(expr.MustTerms("10")), Weak()))
 
 	solver.UpdateVariables()
 	assert.Equal(10.0, solver.Var("x").Value())
@@ -177,9 +177,9 @@ func TestInconsistent1(t *testing.T) {
 	}
 
 	solver := Solver()
-	err := solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("x"))(expr.EQ)(expr.Terms("10.0")), Required()))
+	err := solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.EQ)(expr.MustTerms("10.0")), Required()))
 	assert.Nil(err)
-	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("x"))(expr.EQ)(expr.Terms("5.0")), Required()))
+	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.EQ)(expr.MustTerms("5.0")), Required()))
 	assert.NotNil(err)
 	assert.True(strings.Contains(err.Error(), "UnsatisfiableConstraint"))
 }
@@ -192,9 +192,9 @@ func TestInconsistent2(t *testing.T) {
 	}
 
 	solver := Solver()
-	err := solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("x"))(expr.GEQ)(expr.Terms("10.0")), Required()))
+	err := solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.GEQ)(expr.MustTerms("10.0")), Required()))
 	assert.Nil(err)
-	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("x"))(expr.LEQ)(expr.Terms("5.0")), Required()))
+	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.LEQ)(expr.MustTerms("5.0")), Required()))
 	assert.NotNil(err)
 	assert.True(strings.Contains(err.Error(), "UnsatisfiableConstraint"))
 }
@@ -249,17 +249,17 @@ func TestInconsistent3(t *testing.T) {
 	}
 
 	solver := Solver()
-	err := solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("w"))(expr.GEQ)(expr.Terms("10.0")), Required()))
+	err := solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("w"))(expr.GEQ)(expr.MustTerms("10.0")), Required()))
 	assert.Nil(err)
-	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("x"))(expr.GEQ)(expr.Terms("w")), Required()))
+	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.GEQ)(expr.MustTerms("w")), Required()))
 	assert.Nil(err)
-	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("y"))(expr.GEQ)(expr.Terms("x")), Required()))
+	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("y"))(expr.GEQ)(expr.MustTerms("x")), Required()))
 	assert.Nil(err)
-	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("z"))(expr.GEQ)(expr.Terms("y")), Required()))
+	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("z"))(expr.GEQ)(expr.MustTerms("y")), Required()))
 	assert.Nil(err)
-	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("z"))(expr.GEQ)(expr.Terms("8.0")), Required()))
+	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("z"))(expr.GEQ)(expr.MustTerms("8.0")), Required()))
 	assert.Nil(err)
-	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.Terms("z"))(expr.LEQ)(expr.Terms("4.0")), Required()))
+	err = solver.AddConstraint(NewConstraint(expr.Eqn(expr.MustTerms("z"))(expr.LEQ)(expr.MustTerms("4.0")), Required()))
 	assert.NotNil(err)
 	assert.True(strings.Contains(err.Error(), "UnsatisfiableConstraint"))
 }
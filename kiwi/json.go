@@ -0,0 +1,97 @@
+package kiwi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/noypi/math0/expr"
+)
+
+var g_strengthNames = map[string]StrengthType{
+	"required": _Required,
+	"strong":   _Strong,
+	"medium":   _Medium,
+	"weak":     _Weak,
+}
+
+// JSON of a constraint, the expression as in the expr package and the
+// strength either named or a number:
+//
+//	{"strength": "required", "relation": "<=", "expression": {"terms": [...]}}
+//
+// The relation is written as is only by an Encoder with
+// SetEscapeHTML(false), json.Marshal escapes it as e.g. "\u003c=".
+// Variables are decoded with expr.EqnBuilder_VarConstructor, which should
+// return *Variable for the solver.
+type _JSONConstraint struct {
+	Strength   StrengthType    `json:"strength"`
+	Relation   expr.Relation   `json:"relation"`
+	Expression json.RawMessage `json:"expression"`
+}
+
+// MarshalJSON writes "required", "strong", "medium" or "weak", or the
+// number for other strengths.
+func (this StrengthType) MarshalJSON() ([]byte, error) {
+	for name, s := range g_strengthNames {
+		if s == this {
+			return json.Marshal(name)
+		}
+	}
+	return json.Marshal(float64(this))
+}
+
+func (this *StrengthType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); nil == err {
+		s, has := g_strengthNames[name]
+		if !has {
+			return fmt.Errorf("kiwi: unknown strength %q", name)
+		}
+		*this = s
+		return nil
+	}
+
+	var f float64
+	if err := json.Unmarshal(data, &f); nil != err {
+		return err
+	}
+	*this = clipStrength(StrengthType(f))
+	return nil
+}
+
+func (this Constraint) MarshalJSON() ([]byte, error) {
+	bb, err := expr.MarshalExpr(this.expression)
+	if nil != err {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	err = enc.Encode(_JSONConstraint{
+		Strength:   this.strength,
+		Relation:   this.relation,
+		Expression: bb,
+	})
+	if nil != err {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func (this *Constraint) UnmarshalJSON(data []byte) error {
+	var o _JSONConstraint
+	if err := json.Unmarshal(data, &o); nil != err {
+		return err
+	}
+	if 0 == len(o.Expression) {
+		return fmt.Errorf("kiwi: constraint has no expression")
+	}
+	e, err := expr.UnmarshalExpr(o.Expression)
+	if nil != err {
+		return err
+	}
+	this.strength, this.relation, this.expression = o.Strength, o.Relation, e
+	return nil
+}
@@ -0,0 +1,69 @@
+package kiwi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/noypi/math0/expr"
+	. "github.com/noypi/math0/kiwi"
+	assertpkg "github.com/stretchr/testify/assert"
+)
+
+func TestJSON_Constraint(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	expr.EqnBuilder_VarConstructor = func(name string, power float64) expr.IVariable {
+		return Var(name)
+	}
+
+	cs := []*Constraint{
+		NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.EQ)(expr.MustTerms("100")), Weak()),
+		NewConstraint(expr.Eqn(expr.MustTerms("x"))(expr.LEQ)(expr.MustTerms("10")), Required()),
+		NewConstraint(expr.Eqn(expr.MustTerms("y"))(expr.GEQ)(expr.MustTerms("x", "2")), Strong()),
+	}
+	bb, err := json.Marshal(cs)
+	assert.Nil(err)
+	assert.Contains(string(bb), `{"strength":"required","relation":"\u003c=","expression":{"terms":[{"c":-10},{"c":1,"vars":[{"name":"x"}]}]}}`)
+
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	assert.Nil(enc.Encode(cs))
+	assert.Contains(buf.String(), `{"strength":"required","relation":"<=","expression":{"terms":[{"c":-10},{"c":1,"vars":[{"name":"x"}]}]}}`)
+
+	var cs2 []*Constraint
+	assert.Nil(json.Unmarshal(bb, &cs2))
+	assert.Equal(len(cs), len(cs2))
+	for i := range cs {
+		assert.Equal(cs[i].Dump(), cs2[i].Dump(), "i=%d", i)
+	}
+
+	solver := Solver()
+	for _, c := range cs2 {
+		solver.AddConstraint(c)
+	}
+	x, y := solver.Var("x"), solver.Var("y")
+	solver.UpdateVariables()
+	assert.Equal(10.0, x.Value())
+	assert.Equal(12.0, y.Value())
+}
+
+func TestJSON_Strength(t *testing.T) {
+	assert := assertpkg.New(t)
+
+	for _, s := range []StrengthType{Required(), Strong(), Medium(), Weak(), StrengthType(2.5)} {
+		bb, err := json.Marshal(s)
+		assert.Nil(err)
+		var s2 StrengthType
+		assert.Nil(json.Unmarshal(bb, &s2), string(bb))
+		assert.Equal(s, s2)
+	}
+
+	bb, _ := json.Marshal(Medium())
+	assert.Equal(`"medium"`, string(bb))
+
+	var s StrengthType
+	assert.NotNil(json.Unmarshal([]byte(`"stronger"`), &s))
+	assert.NotNil(json.Unmarshal([]byte(`true`), &s))
+}